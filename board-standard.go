@@ -4,6 +4,7 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/csv"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"os"
 	"strconv"
 	"sort"
+	"strings"
 )
 
 import (
@@ -27,14 +29,14 @@ import (
 /****************************************************************************************/
 
 func (f *Board_BoolPacked) isSet_safe(x, y int) bool {
-	if x<0 || x>=board_width || y<0 || y>=board_height {
+	if x<0 || x>=f.w || y<0 || y>=f.h {
 		return false
 	}
 	return f.isSet(x,y)
 }
 
 func (f *Board_BoolPacked) Set_safe(x, y int, b bool) {
-	if x<0 || x>=board_width || y<0 || y>=board_height {
+	if x<0 || x>=f.w || y<0 || y>=f.h {
 		return 
 	}
 	f.Set(x,y,b)
@@ -43,9 +45,15 @@ func (f *Board_BoolPacked) Set_safe(x, y int, b bool) {
 
 // puts Board in a random state
 func (f *Board_BoolPacked) UniformRandom(pct float32) {
+	f.UniformRandomR(pct, rand_global)
+}
+
+// UniformRandomR is UniformRandom drawing from r : Boards filled from identically seeded generators are identical,
+// and workers with their own generators don't contend for the global one's lock
+func (f *Board_BoolPacked) UniformRandomR(pct float32, r *rand.Rand) {
 	for y := 0; y < f.h; y++ {
 		for x := 0; x < f.w; x++ {
-			f.Set(x, y, (rand.Float32() < pct))
+			f.Set(x, y, (r.Float32() < pct))
 		}
 	}
 }
@@ -66,6 +74,31 @@ func (f *Board_BoolPacked) LoadString(s string) {
 	}
 }
 
+// SetCells sets each of the given {x,y} coordinates alive (leaving everything else as it was)
+func (f *Board_BoolPacked) SetCells(cells [][2]int) {
+	for _, c := range cells {
+		f.Set(c[0], c[1], true)
+	}
+}
+
+// ToGoLiteral returns Go source that rebuilds this board, for pasting known patterns into test fixtures
+func (f *Board_BoolPacked) ToGoLiteral(varName string) string {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%s := NewBoard_BoolPacked(%d, %d)\n", varName, f.w, f.h))
+	buf.WriteString(fmt.Sprintf("%s.SetCells([][2]int{", varName))
+	sep := ""
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < f.w; x++ {
+			if f.isSet(x, y) {
+				buf.WriteString(fmt.Sprintf("%s{%d, %d}", sep, x, y))
+				sep = ", "
+			}
+		}
+	}
+	buf.WriteString("})\n")
+	return buf.String()
+}
+
 func (f *Board_BoolPacked) LoadArray(csv_strings []string) {
 	x := 0
 	y := 0
@@ -83,25 +116,82 @@ func (f *Board_BoolPacked) LoadArray(csv_strings []string) {
 	}
 }
 
-// Next returns the state of the specified cell at the next time step.
+// FromFlat builds a board from a flattened (e.g. numpy) array, where nonzero means alive.
+// Row y starts at data[y*stride], so a stride larger than w picks out a sub-array view
+func FromFlat(w, h int, data []uint8, stride int) (*Board_BoolPacked, error) {
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("bad dimensions %dx%d", w, h)
+	}
+	if stride < w {
+		return nil, fmt.Errorf("stride %d is less than width %d", stride, w)
+	}
+	if needed := (h-1)*stride + w; len(data) < needed {
+		return nil, fmt.Errorf("flat data has %d entries, %dx%d with stride %d needs %d", len(data), w, h, stride, needed)
+	}
+
+	f := NewBoard_BoolPacked(w, h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			f.Set(x, y, data[y*stride+x] != 0)
+		}
+	}
+	return f, nil
+}
+
+// Next returns the state of the specified cell at the next time step (Conway rules, boundary as set by SetWrap).
 func (f *Board_BoolPacked) IterateCell(x, y int) bool {
-	// Count the adjacent cells that are alive.
+	return f.IterateCellRule(x, y, RuleConway)
+}
+
+// IterateCellRule is IterateCell under any rule (the boundary follows the board's wrap setting)
+func (f *Board_BoolPacked) IterateCellRule(x, y int, rule Rule) bool {
+	if f.wrap {
+		return f.NextCellState(x, y, rule, BoundaryWrap)
+	}
+	return f.NextCellState(x, y, rule, BoundaryDead)
+}
+
+// NextCellState returns the state of the specified cell at the next time step, under any rule and boundary
+func (f *Board_BoolPacked) NextCellState(x, y int, rule Rule, boundary BoundaryMode) bool {
+	return rule.next(f.isSet(x, y), f.neighbour_count(x, y, boundary))
+}
+
+// Count the adjacent cells that are alive.
+func (f *Board_BoolPacked) neighbour_count(x, y int, boundary BoundaryMode) int {
 	alive := 0
 	for i := -1; i <= 1; i++ {
 		for j := -1; j <= 1; j++ {
-			if (j != 0 || i != 0) && f.isSet(x+i, y+j) {
+			if j == 0 && i == 0 {
+				continue
+			}
+			if boundary == BoundaryWrap && f.isSet_wrap(x+i, y+j) ||
+				boundary == BoundaryDead && f.isSet_safe(x+i, y+j) {
 				alive++
 			}
 		}
 	}
-	// Return next state according to the game rules:
-	//   exactly 3 neighbors: on,
-	//   exactly 2 neighbors: maintain current state,
-	//   otherwise: off.
-	return alive == 3 || alive == 2 && f.isSet(x, y)
+	return alive
+}
+
+// NeighborCounts gives the number of live neighbours (0..8) of every cell, indexed [y][x]
+// (boundary as set by SetWrap)
+func (f *Board_BoolPacked) NeighborCounts() [][]int {
+	boundary := BoundaryDead
+	if f.wrap {
+		boundary = BoundaryWrap
+	}
+	counts := make([][]int, f.h)
+	for y := 0; y < f.h; y++ {
+		counts[y] = make([]int, f.w)
+		for x := 0; x < f.w; x++ {
+			counts[y][x] = f.neighbour_count(x, y, boundary)
+		}
+	}
+	return counts
 }
 
 func (f *Board_BoolPacked) Iterate_Generic(next *Board_BoolPacked) {
+	next.wrap = f.wrap
 	// Update the state of the next field (next) in-place from the current field (f).
 	for y := 0; y < f.h; y++ {
 		for x := 0; x < f.w; x++ {
@@ -110,6 +200,296 @@ func (f *Board_BoolPacked) Iterate_Generic(next *Board_BoolPacked) {
 	}
 }
 
+// EdgePressure counts the live cells on the outermost ring of the board.
+// These are the cells whose evolution is truncated by the dead boundary, so a high count 
+// flags boards that won't behave like the same pattern on an infinite plane
+func (f *Board_BoolPacked) EdgePressure() int {
+	count := 0
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < f.w; x++ {
+			if (x == 0 || x == f.w-1 || y == 0 || y == f.h-1) && f.isSet(x, y) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Centroid is the average (x,y) coordinate of the live cells (ok=false for an empty board)
+func (f *Board_BoolPacked) Centroid() (cx, cy float64, ok bool) {
+	sum_x, sum_y, count := 0, 0, 0
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < f.w; x++ {
+			if f.isSet(x, y) {
+				sum_x += x
+				sum_y += y
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return 0, 0, false
+	}
+	return float64(sum_x) / float64(count), float64(sum_y) / float64(count), true
+}
+
+// Autocorrelation of the live-cell field (mean removed, normalised by the variance), indexed [dy+maxLag][dx+maxLag].
+// Each lag averages over the pairs of cells that overlap on the board, so [maxLag][maxLag] is 1.
+// Periodic patterns show peaks at multiples of their period, noise is near 0 away from the origin.
+// A board with no variance at all (empty or full) gives all zeros
+func (f *Board_BoolPacked) Autocorrelation(maxLag int) [][]float64 {
+	v := make([][]float64, f.h)
+	mean := 0.0
+	for y := 0; y < f.h; y++ {
+		v[y] = make([]float64, f.w)
+		for x := 0; x < f.w; x++ {
+			if f.isSet(x, y) {
+				v[y][x] = 1
+				mean++
+			}
+		}
+	}
+	mean /= float64(f.w * f.h)
+	variance := mean * (1 - mean)
+	for y := range v {
+		for x := range v[y] {
+			v[y][x] -= mean
+		}
+	}
+
+	corr := make([][]float64, 2*maxLag+1)
+	for dy := -maxLag; dy <= maxLag; dy++ {
+		corr[dy+maxLag] = make([]float64, 2*maxLag+1)
+		for dx := -maxLag; dx <= maxLag; dx++ {
+			sum, pairs := 0.0, 0
+			for y := 0; y < f.h; y++ {
+				for x := 0; x < f.w; x++ {
+					if x+dx < 0 || x+dx >= f.w || y+dy < 0 || y+dy >= f.h {
+						continue
+					}
+					sum += v[y][x] * v[y+dy][x+dx]
+					pairs++
+				}
+			}
+			if pairs > 0 && variance > 0 {
+				corr[dy+maxLag][dx+maxLag] = sum / float64(pairs) / variance
+			}
+		}
+	}
+	return corr
+}
+
+// Crop returns a new board holding the cells of the rectangle (minX,minY)-(maxX,maxY) inclusive
+// (e.g. from BoundingBox).  The rectangle is clamped to the board, and if nothing is left the board is 0x0
+func (f *Board_BoolPacked) Crop(minX, minY, maxX, maxY int) *Board_BoolPacked {
+	if minX < 0 {
+		minX = 0
+	}
+	if minY < 0 {
+		minY = 0
+	}
+	if maxX > f.w-1 {
+		maxX = f.w-1
+	}
+	if maxY > f.h-1 {
+		maxY = f.h-1
+	}
+	if minX > maxX || minY > maxY {
+		return NewBoard_BoolPacked(0, 0)
+	}
+	cropped := NewBoard_BoolPacked(maxX-minX+1, maxY-minY+1)
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			cropped.Set(x-minX, y-minY, f.isSet(x, y))
+		}
+	}
+	return cropped
+}
+
+// How Paste combines the pasted cells with what is already there
+type PasteMode int
+
+const (
+	PasteCopy PasteMode = iota // The pasted rectangle replaces what was there (dead cells included)
+	PasteOr                    // Live cells are added
+	PasteXor                   // Live cells toggle what was there
+)
+
+// Paste stamps src onto dst with its top-left corner at (offX,offY).  Whatever falls outside dst is clipped
+func (dst *Board_BoolPacked) Paste(src *Board_BoolPacked, offX, offY int, mode PasteMode) {
+	for y := 0; y < src.h; y++ {
+		for x := 0; x < src.w; x++ {
+			dx, dy := x+offX, y+offY
+			if dx < 0 || dx >= dst.w || dy < 0 || dy >= dst.h {
+				continue
+			}
+			switch mode {
+			case PasteCopy:
+				dst.Set(dx, dy, src.isSet(x, y))
+			case PasteOr:
+				dst.Set(dx, dy, dst.isSet(dx, dy) || src.isSet(x, y))
+			case PasteXor:
+				dst.Set(dx, dy, dst.isSet(dx, dy) != src.isSet(x, y))
+			}
+		}
+	}
+}
+
+// Quadrants splits the board into {top-left, top-right, bottom-left, bottom-right}.
+// For odd dimensions, the extra row/column goes to the bottom/right quadrants
+func (f *Board_BoolPacked) Quadrants() [4]*Board_BoolPacked {
+	mid_x, mid_y := f.w/2, f.h/2
+	return [4]*Board_BoolPacked{
+		f.Crop(0, 0, mid_x-1, mid_y-1),
+		f.Crop(mid_x, 0, f.w-1, mid_y-1),
+		f.Crop(0, mid_y, mid_x-1, f.h-1),
+		f.Crop(mid_x, mid_y, f.w-1, f.h-1),
+	}
+}
+
+// Builds a w x h board whose cell (x,y) is f's cell source(x,y)
+func (f *Board_BoolPacked) transformed(w, h int, source func(x, y int) (int, int)) *Board_BoolPacked {
+	t := NewBoard_BoolPacked(w, h)
+	t.wrap = f.wrap
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			t.Set(x, y, f.isSet(source(x, y)))
+		}
+	}
+	return t
+}
+
+// Rotate90 turns the board a quarter clockwise, so the result is f.h wide and f.w high
+func (f *Board_BoolPacked) Rotate90() *Board_BoolPacked {
+	return f.transformed(f.h, f.w, func(x, y int) (int, int) { return y, f.h-1-x })
+}
+
+// Rotate180 turns the board half way round
+func (f *Board_BoolPacked) Rotate180() *Board_BoolPacked {
+	return f.transformed(f.w, f.h, func(x, y int) (int, int) { return f.w-1-x, f.h-1-y })
+}
+
+// Rotate270 turns the board a quarter anticlockwise, so the result is f.h wide and f.w high
+func (f *Board_BoolPacked) Rotate270() *Board_BoolPacked {
+	return f.transformed(f.h, f.w, func(x, y int) (int, int) { return f.w-1-y, x })
+}
+
+// FlipH mirrors the board left-right
+func (f *Board_BoolPacked) FlipH() *Board_BoolPacked {
+	return f.transformed(f.w, f.h, func(x, y int) (int, int) { return f.w-1-x, y })
+}
+
+// FlipV mirrors the board top-bottom
+func (f *Board_BoolPacked) FlipV() *Board_BoolPacked {
+	return f.transformed(f.w, f.h, func(x, y int) (int, int) { return x, f.h-1-y })
+}
+
+// Which of the board symmetries (see Symmetries) hold
+type SymFlags int
+
+const (
+	SymMirrorH       SymFlags = 1 << iota // Unchanged by FlipH (mirrored left-right)
+	SymMirrorV                            // Unchanged by FlipV (mirrored top-bottom)
+	SymRotate180                          // Unchanged by Rotate180
+	SymDiagonal                           // Unchanged by reflecting in the top-left to bottom-right diagonal (square only)
+	SymAntiDiagonal                       // Unchanged by reflecting in the top-right to bottom-left diagonal (square only)
+)
+
+// Symmetries reports the reflections/rotations that leave the board unchanged
+// (a board with a symmetric end may well have a symmetric start)
+func (f *Board_BoolPacked) Symmetries() SymFlags {
+	flags := SymFlags(0)
+	if f.FlipH().CompareTo(f, nil) == 0 {
+		flags |= SymMirrorH
+	}
+	if f.FlipV().CompareTo(f, nil) == 0 {
+		flags |= SymMirrorV
+	}
+	if f.Rotate180().CompareTo(f, nil) == 0 {
+		flags |= SymRotate180
+	}
+	if f.w == f.h {
+		if f.Rotate90().FlipH().CompareTo(f, nil) == 0 {
+			flags |= SymDiagonal
+		}
+		if f.Rotate90().FlipV().CompareTo(f, nil) == 0 {
+			flags |= SymAntiDiagonal
+		}
+	}
+	return flags
+}
+
+// MergeByConfidence takes each cell from whichever of a and b is more confident about it (confidences indexed [y][x]).
+// Ties go to a
+func MergeByConfidence(a, b *Board_BoolPacked, confA, confB [][]float64) *Board_BoolPacked {
+	merged := NewBoard_BoolPacked(a.w, a.h)
+	for y := 0; y < a.h; y++ {
+		for x := 0; x < a.w; x++ {
+			if confB[y][x] > confA[y][x] {
+				merged.Set(x, y, b.isSet(x, y))
+			} else {
+				merged.Set(x, y, a.isSet(x, y))
+			}
+		}
+	}
+	return merged
+}
+
+// Period returns the smallest p in 1..maxPeriod after which the board is back in exactly the same state
+// (1 for a still life), or 0 if it doesn't recur that soon.  Unlike OscillatorPhases, nothing is kept
+func (f *Board_BoolPacked) Period(maxPeriod int) int {
+	l := NewBoardIterator(f.w, f.h)
+	l.current.CopyFrom(f)
+	for p := 1; p <= maxPeriod; p++ {
+		l.Iterate(1)
+		if l.current.CompareTo(f, nil) == 0 {
+			return p
+		}
+	}
+	return 0
+}
+
+// OscillatorPhases returns the distinct phases of the oscillator b belongs to, in order starting with b itself.
+// ok is false (and phases nil) if b doesn't recur within maxPeriod steps.  Still lifes have the one phase
+func OscillatorPhases(b *Board_BoolPacked, maxPeriod int) (phases []*Board_BoolPacked, ok bool) {
+	first := NewBoard_BoolPacked(b.w, b.h)
+	first.CopyFrom(b)
+	phases = []*Board_BoolPacked{first}
+
+	l := NewBoardIterator(b.w, b.h)
+	l.current.CopyFrom(b)
+	for step := 1; step <= maxPeriod; step++ {
+		l.Iterate(1)
+		if l.current.CompareTo(b, nil) == 0 {
+			return phases, true
+		}
+		phase := NewBoard_BoolPacked(b.w, b.h)
+		phase.CopyFrom(l.current)
+		phases = append(phases, phase)
+	}
+	return nil, false
+}
+
+// NormalizePhase looks for b recurring within maxPeriod steps (i.e. b is an oscillator phase), 
+// and if so returns the canonical phase : the one with the (lexicographically) smallest compact string.
+// The offset is the number of steps forward from b to that phase.  Non-oscillators come back as a copy of b, offset 0
+func NormalizePhase(b *Board_BoolPacked, maxPeriod int) (*Board_BoolPacked, int) {
+	phases, ok := OscillatorPhases(b, maxPeriod)
+	if !ok {
+		canonical := NewBoard_BoolPacked(b.w, b.h)
+		canonical.CopyFrom(b)
+		return canonical, 0
+	}
+
+	offset, smallest := 0, phases[0].toCompactString()
+	for i, phase := range phases {
+		if key := phase.toCompactString(); key < smallest {
+			offset, smallest = i, key
+		}
+	}
+	return phases[offset], offset
+}
+
 // String returns the game board as a string.
 func (f *Board_BoolPacked) String() string {
 	var buf bytes.Buffer
@@ -131,6 +511,32 @@ func (f *Board_BoolPacked) String() string {
 	return buf.String()
 }
 
+const ansi_live_color = "\x1b[32m" // green
+const ansi_reset = "\x1b[0m"
+
+// StringANSI returns the game board for a terminal : live cells are blocks, dead cells are spaces.
+// Pass use_color=false when the output isn't a TTY, to get the same layout without escape codes
+func (f *Board_BoolPacked) StringANSI(use_color bool) string {
+	var buf bytes.Buffer
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < f.w; x++ {
+			if f.isSet(x, y) {
+				if use_color {
+					buf.WriteString(ansi_live_color)
+				}
+				buf.WriteString("\u2588")
+				if use_color {
+					buf.WriteString(ansi_reset)
+				}
+			} else {
+				buf.WriteByte(' ')
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
 // Returns the game board as a string of 1s and 0s with commas (with a preceeding ',')
 func (f *Board_BoolPacked) toCSV() string {
 	var buf bytes.Buffer
@@ -172,6 +578,169 @@ func (f *Board_BoolPacked) fromCompactString(buf string)  {
 }
 
 
+// Appends a run-length token in RLE style : "3o", "b", "2$", ...
+func rle_token(buf *bytes.Buffer, count int, tag byte) {
+	if count > 1 {
+		buf.WriteString(strconv.Itoa(count))
+	}
+	buf.WriteByte(tag)
+}
+
+// Returns the cells in the run-length encoded form of the standard Life .rle format 
+// ('o' alive, 'b' dead, '$' end of row, '!' end of pattern), without header or line-wrapping
+func (f *Board_BoolPacked) rle_body() string {
+	var buf bytes.Buffer
+	row_written := 0 // Row the 'cursor' is on
+	for y := 0; y < f.h; y++ {
+		// Trailing dead cells on a row are implied
+		last_alive := -1
+		for x := 0; x < f.w; x++ {
+			if f.isSet(x, y) {
+				last_alive = x
+			}
+		}
+		if last_alive < 0 {
+			continue
+		}
+		if y > row_written {
+			rle_token(&buf, y-row_written, '$')
+			row_written = y
+		}
+		for x := 0; x <= last_alive; {
+			alive := f.isSet(x, y)
+			run := 0
+			for ; x <= last_alive && f.isSet(x, y) == alive; x++ {
+				run++
+			}
+			tag := byte('b')
+			if alive {
+				tag = 'o'
+			}
+			rle_token(&buf, run, tag)
+		}
+	}
+	buf.WriteByte('!')
+	return buf.String()
+}
+
+// ComplexityScore is the length of the board's RLE encoding :
+// Structured patterns compress well (low score), noise doesn't
+func (f *Board_BoolPacked) ComplexityScore() int {
+	return len(f.rle_body())
+}
+
+// WriteRLE writes the board as a standard .rle pattern : header, then the body wrapped at 70 characters
+func (f *Board_BoolPacked) WriteRLE(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("x = %d, y = %d, rule = B3/S23\n", f.w, f.h))
+
+	line_len := 0
+	body := f.rle_body()
+	for i := 0; i < len(body); {
+		// A token is a run count (if any) and its tag : these aren't split across lines
+		j := i
+		for body[j] >= '0' && body[j] <= '9' {
+			j++
+		}
+		token := body[i : j+1]
+		if line_len+len(token) > 70 {
+			buf.WriteByte('\n')
+			line_len = 0
+		}
+		buf.WriteString(token)
+		line_len += len(token)
+		i = j + 1
+	}
+	buf.WriteByte('\n')
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// LoadRLE reads a standard .rle pattern ('#' comment lines are skipped, and the rule is ignored).
+// The board is cleared and the pattern centred on it : A board with no size yet takes the pattern's size
+func (f *Board_BoolPacked) LoadRLE(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	pattern_w, pattern_h := -1, -1
+	var body bytes.Buffer
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case pattern_w < 0 && strings.HasPrefix(line, "x"):
+			// e.g. "x = 3, y = 3, rule = B3/S23"
+			for _, field := range strings.Split(line, ",") {
+				parts := strings.SplitN(field, "=", 2)
+				if len(parts) != 2 {
+					return fmt.Errorf("bad RLE header '%s'", line)
+				}
+				value, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+				switch strings.TrimSpace(parts[0]) {
+				case "x":
+					pattern_w = value
+				case "y":
+					pattern_h = value
+				default:
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("bad RLE header '%s'", line)
+				}
+			}
+		default:
+			body.WriteString(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if pattern_w < 0 || pattern_h < 0 {
+		return fmt.Errorf("RLE header 'x = .., y = ..' not found")
+	}
+
+	if f.w == 0 && f.h == 0 {
+		*f = *NewBoard_BoolPacked(pattern_w, pattern_h)
+	}
+	if pattern_w > f.w || pattern_h > f.h {
+		return fmt.Errorf("RLE pattern %dx%d doesn't fit on a %dx%d board", pattern_w, pattern_h, f.w, f.h)
+	}
+	f.Clear()
+	offset_x, offset_y := (f.w-pattern_w)/2, (f.h-pattern_h)/2
+
+	x, y, count := 0, 0, 0
+	for _, c := range body.String() {
+		switch {
+		case c >= '0' && c <= '9':
+			count = count*10 + int(c-'0')
+			continue
+		case c == '!':
+			return nil
+		}
+		if count == 0 {
+			count = 1
+		}
+		switch {
+		case c == '$':
+			x, y = 0, y+count
+		case c == 'b' || c == '.':
+			x += count
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z': // 'o', or any other live state
+			if x+count > pattern_w || y >= pattern_h {
+				return fmt.Errorf("RLE pattern runs outside its %dx%d header size", pattern_w, pattern_h)
+			}
+			for ; count > 0; count-- {
+				f.Set(offset_x+x, offset_y+y, true)
+				x++
+			}
+		default:
+			return fmt.Errorf("unexpected '%c' in RLE pattern", c)
+		}
+		count = 0
+	}
+	return fmt.Errorf("RLE pattern has no terminating '!'")
+}
+
 func (f *Board_BoolPacked) AddToStats(bs *BoardStats) {
 	for y := 0; y < f.h; y++ {
 		for x := 0; x < f.w; x++ {
@@ -212,9 +781,45 @@ func (bs *BoardStats) MisMatchBy(mismatch int) {
 	bs.mismatch_amount = mismatch
 }
 
+// Confidence is the fraction of the boards added so far with cell (x,y) alive (0 if none have been added)
+func (bs *BoardStats) Confidence(x, y int) float32 {
+	if bs.count == 0 {
+		return 0
+	}
+	return float32(bs.freq[y][x]) / float32(bs.count)
+}
+
+// WriteCSV writes the ensemble's per-cell Confidence as a grid (one line per row, a column per x), after a 
+// '#' comment line with the count and mismatch_amount (numpy.loadtxt and pandas' comment='#' skip it)
+func (bs *BoardStats) WriteCSV(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# count=%d mismatch_amount=%d\n", bs.count, bs.mismatch_amount)
+	for y := 0; y < bs.h; y++ {
+		fields := make([]string, bs.w)
+		for x := 0; x < bs.w; x++ {
+			fields[x] = strconv.FormatFloat(float64(bs.Confidence(x, y)), 'g', -1, 32)
+		}
+		fmt.Fprintln(bw, strings.Join(fields, ","))
+	}
+	return bw.Flush()
+}
+
+// Threshold turns the ensemble into a single board : alive wherever Confidence >= t.
+// Unlike ThresholdStats, the threshold is a fraction, and reaching it is enough
+func (bs *BoardStats) Threshold(t float32) *Board_BoolPacked {
+	b := NewBoard_BoolPacked(bs.w, bs.h)
+	for y := 0; y < bs.h; y++ {
+		for x := 0; x < bs.w; x++ {
+			b.Set(x, y, bs.Confidence(x, y) >= t)
+		}
+	}
+	return b
+}
+
 // BoardIterator stores the state of a round of Conway's Game of Life.
 type BoardIterator struct {
 	current, temp_internal_only *Board_BoolPacked
+	table *[512]bool // RuleTransitionTable of the rule : nil for Conway's (which has a faster path)
 }
 
 // BoardIterator returns a new Life game state
@@ -225,15 +830,39 @@ func NewBoardIterator(w, h int) *BoardIterator {
 	}
 }
 
+// NewBoardIteratorRule is NewBoardIterator for a game played under any rule (e.g. from ParseRule)
+func NewBoardIteratorRule(w, h int, rule Rule) *BoardIterator {
+	bi := NewBoardIterator(w, h)
+	if rule != RuleConway {
+		table := RuleTransitionTable(rule)
+		bi.table = &table
+	}
+	return bi
+}
+
 // Step advances the game by one instant, recomputing and updating all cells.
 func (bi *BoardIterator) Iterate(n int) {
 	for i := 0; i < n; i++ {
-		bi.current.Iterate(bi.temp_internal_only)
+		if bi.table != nil {
+			bi.current.Iterate1LookupRule(bi.temp_internal_only, bi.table)
+		} else {
+			bi.current.Iterate(bi.temp_internal_only)
+		}
 		// Now swap boards, to put the result in prime position
 		bi.current, bi.temp_internal_only = bi.temp_internal_only, bi.current
 	}
 }
 
+// Record advances the iterator n steps, returning copies of the board before and after each step (n+1 boards)
+func (bi *BoardIterator) Record(n int) []*Board_BoolPacked {
+	boards := []*Board_BoolPacked{bi.current.Clone()}
+	for i := 0; i < n; i++ {
+		bi.Iterate(1)
+		boards = append(boards, bi.current.Clone())
+	}
+	return boards
+}
+
 type LifeProblem struct {
 	id         int
 	start, end *Board_BoolPacked
@@ -242,6 +871,10 @@ type LifeProblem struct {
 }
 
 func (problem *LifeProblem) CreateFake() {
+	problem.CreateFakeR(rand_global)
+}
+
+func (problem *LifeProblem) CreateFakeR(r *rand.Rand) {
 	id := problem.id
 	steps := problem.steps
 	
@@ -250,11 +883,11 @@ func (problem *LifeProblem) CreateFake() {
 	
 	for found:=false; !found; {
 		// create a board with a random initial density U(0..1) 
-		uniform := rand.Float32()
+		uniform := r.Float32()
 		fmt.Printf("id[%6d].steps=%d, Uniform Density = %6.4f\n", id, steps, uniform)
 		
 		initial := NewBoard_BoolPacked(board_width, board_height)
-		initial.UniformRandom(uniform)
+		initial.UniformRandomR(uniform, r)
 		//fmt.Println(initial)
 		
 		// transition it forwards 5 times
@@ -285,29 +918,143 @@ func (problem *LifeProblem) CreateFake() {
 	problem.end = end
 }
 
+// Verify iterates a candidate start forward by the problem's steps, and compares the result with the known end
+// (which must be the same size)
+func (problem *LifeProblem) Verify(candidate *Board_BoolPacked) (matches bool, wrongCells int) {
+	// The solvers call this a lot, so the scratch boards come from a pool
+	pool := StandardBoardPool
+	if candidate.w != pool.w || candidate.h != pool.h {
+		pool = NewBoardPool(candidate.w, candidate.h) // Just a one-off
+	}
+	current, next := pool.Get(), pool.Get()
+	defer pool.Put(current)
+	defer pool.Put(next)
+
+	current.CopyFrom(candidate)
+	for i := 0; i < problem.steps; i++ {
+		current.Iterate(next)
+		current, next = next, current
+	}
+	wrongCells = current.HammingDistance(problem.end)
+	return wrongCells == 0, wrongCells
+}
+
+// DumpProblemAsTest writes a standalone Go test file reproducing a (wrong) prediction for the problem : 
+// it rebuilds the boards with ToGoLiteral and asserts the mismatch observed now, ready to paste into a test suite
+func DumpProblemAsTest(p LifeProblem, predicted *Board_BoolPacked, path string) error {
+	_, mismatch := p.Verify(predicted)
+
+	var buf bytes.Buffer
+	indent := func(code string) { // ToGoLiteral lines, indented to sit inside the test function
+		for _, line := range strings.SplitAfter(code, "\n") {
+			if line != "" {
+				buf.WriteString("\t" + line)
+			}
+		}
+	}
+
+	buf.WriteString("// An implementation of Conway's Game of Life.\n// See reverse-gol.go for build/run\n\n")
+	buf.WriteString("package main\n\nimport (\n\t\"testing\"\n)\n\n")
+	buf.WriteString(fmt.Sprintf("// Problem %d (steps=%d) : the prediction misses the end by %d cells\n", p.id, p.steps, mismatch))
+	buf.WriteString(fmt.Sprintf("func TestProblem%d(t *testing.T) {\n", p.id))
+	if p.start != nil {
+		indent(p.start.ToGoLiteral("start"))
+	} else {
+		buf.WriteString("\tvar start *Board_BoolPacked // Test problem : no known start\n")
+	}
+	indent(p.end.ToGoLiteral("end"))
+	indent(predicted.ToGoLiteral("predicted"))
+	buf.WriteString(fmt.Sprintf("\n\tproblem := LifeProblem{id: %d, start: start, end: end, steps: %d}\n", p.id, p.steps))
+	buf.WriteString("\t_, mismatch := problem.Verify(predicted)\n")
+	buf.WriteString(fmt.Sprintf("\tif mismatch != %d {\n", mismatch))
+	buf.WriteString(fmt.Sprintf("\t\tt.Errorf(\"mismatch = %%d, want %d\", mismatch)\n", mismatch))
+	buf.WriteString("\t}\n}\n")
+
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// FullTrajectory returns the predicted start followed by each generation up to the predicted end 
+// (steps+1 boards in all), so the last one can be diffed against problem.end
+func (problem LifeProblem) FullTrajectory(predictedStart *Board_BoolPacked) []*Board_BoolPacked {
+	trajectory := make([]*Board_BoolPacked, problem.steps+1)
+	trajectory[0] = NewBoard_BoolPacked(predictedStart.w, predictedStart.h)
+	trajectory[0].CopyFrom(predictedStart)
+	for i := 1; i <= problem.steps; i++ {
+		trajectory[i] = NewBoard_BoolPacked(predictedStart.w, predictedStart.h)
+		trajectory[i-1].Iterate(trajectory[i])
+	}
+	return trajectory
+}
+
+// ActivityMask marks every cell that changes state at some point over the 'steps' generations after start :
+// Still lifes (and empty space) stay clear, while oscillators and moving patterns show up
+func ActivityMask(start *Board_BoolPacked, steps int) *Board_BoolPacked { // OPTIMIZED FOR BoolPacked
+	mask := NewBoard_BoolPacked(start.w, start.h)
+	current, next := start.Clone(), NewBoard_BoolPacked(start.w, start.h)
+	for i := 0; i < steps; i++ {
+		current.Iterate(next)
+		for y := 1; y<=start.h; y++ {
+			mask.s[y] |= current.s[y] ^ next.s[y]
+		}
+		current, next = next, current
+	}
+	return mask
+}
+
+// PopulationDelta is end.Population() - start.Population() : Negative means the pattern shrank over the steps.
+// Only meaningful for training problems (test problems have a blank start)
+func (problem LifeProblem) PopulationDelta() int {
+	if problem.start == nil {
+		return 0
+	}
+	return problem.end.Population() - problem.start.Population()
+}
+
 type LifeProblemSet struct {
 	problem map[int]LifeProblem
 	is_training bool
 	
 	transition_collection []TransitionCollectionList
+	
+	metric *MetricAccumulator // Optional : SolveAll scores each training prediction into this as it completes
+	logger *JSONLogger        // Optional : SolveAll logs a SolveResult for each problem into this
+	
+	results map[int]SolveResult // Filled in by SolveAll (e.g. for SlowestProblems)
 }
 
 // Unlike the db, the ids here match the training.csv and test.csv files exactly
-func (s *LifeProblemSet) load_csv(is_training bool, id_list []int) {
+// The boards in the file are width x height (the Kaggle data is board_width x board_height)
+func (s *LifeProblemSet) LoadCSV(is_training bool, id_list []int, width, height int) error {
 	filename := "data/test.csv"
 	if is_training {
 		filename = "data/train.csv"
-		if id_list[0]>50000 { 
+		if len(id_list)>0 && id_list[0]>50000 { 
 			filename = "data/train_fake.csv"
 		}
 	}
-	s.load_csv_from_file(filename, is_training, true, id_list)
+	return s.load_csv_from_file_sized(filename, ',', is_training, true, id_list, width, height)
 }
 
 // Unlike the db, the ids here match the training.csv and test.csv files exactly
 // is_training means that it contains {start[1-400],stop[1-400]} otherwise {stop[1-400]}
 // has_steps means there is a steps column (true for train+test CSVs, not for submission CSV)
-func (s *LifeProblemSet) load_csv_from_file(filename string, is_training bool, has_steps bool, id_list []int) {
+func (s *LifeProblemSet) load_csv_from_file(filename string, is_training bool, has_steps bool, id_list []int) error {
+	return s.load_csv_from_file_sized(filename, ',', is_training, has_steps, id_list, board_width, board_height)
+}
+
+// LoadCSVWithDelimiter is LoadCSV for files exported with other separators (e.g. ';' or '\t')
+// Quoted fields are handled by encoding/csv, with stray quotes tolerated
+func (s *LifeProblemSet) LoadCSVWithDelimiter(path string, delim rune, is_training bool, id_list []int) error {
+	return s.load_csv_from_file_delimited(path, delim, is_training, true, id_list)
+}
+
+func (s *LifeProblemSet) load_csv_from_file_delimited(filename string, delim rune, is_training bool, has_steps bool, id_list []int) error {
+	return s.load_csv_from_file_sized(filename, delim, is_training, has_steps, id_list, board_width, board_height)
+}
+
+// The general loader : Boards in the file are width x height.
+// Problems read before any error are kept in s
+func (s *LifeProblemSet) load_csv_from_file_sized(filename string, delim rune, is_training bool, has_steps bool, id_list []int, width, height int) error {
 	if s.problem == nil {
 		s.problem = make(map[int]LifeProblem)
 	}
@@ -315,17 +1062,34 @@ func (s *LifeProblemSet) load_csv_from_file(filename string, is_training bool, h
 	
 	file, err := os.Open(filename)
 	if err != nil {
-		fmt.Println("Error:", err)
-		return
+		return fmt.Errorf("loading problems : %w", err)
 	}
 	defer file.Close()
 	reader := csv.NewReader(file)
+	reader.Comma = delim
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1 // Short records are reported below, with their id
+
+	// id, [steps,] then one column per cell of each board
+	cells := width*height
+	columns := 1 + cells
+	if has_steps {
+		columns++
+	}
+	if is_training {
+		columns += cells
+	}
 
 	// First line different
 	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("reading header of %s : %w", filename, err)
+	}
 	if header[0] != "id" {
-		fmt.Println("Bad Header", err)
-		return
+		return fmt.Errorf("bad header in %s : first column is '%s', not 'id'", filename, header[0])
+	}
+	if len(header) < columns {
+		return fmt.Errorf("bad header in %s : %d columns, expected %d", filename, len(header), columns)
 	}
 	//fmt.Println("Header Start: ", header[2:402])
 	//fmt.Println("Header Stop : ", header[402:802])
@@ -344,32 +1108,40 @@ func (s *LifeProblemSet) load_csv_from_file(filename string, is_training bool, h
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			fmt.Println("Error:", err)
-			return
+			return fmt.Errorf("reading %s : %w", filename, err)
 		}
 
 		// record is []string
-		id, _ := strconv.Atoi(record[0])
+		id, err := strconv.Atoi(record[0])
+		if err != nil {
+			return fmt.Errorf("bad id in %s : %w", filename, err)
+		}
 		if id_map[id] {
 			//fmt.Println(record) // record has the type []string
+			if len(record) < columns {
+				return fmt.Errorf("problem[%d] in %s is short : %d columns, expected %d", id, filename, len(record), columns)
+			}
 			
 			steps:=0
 			var data []string
 			
 			if has_steps {
-				steps, _ = strconv.Atoi(record[1])
+				steps, err = strconv.Atoi(record[1])
+				if err != nil {
+					return fmt.Errorf("bad steps for problem[%d] in %s : %w", id, filename, err)
+				}
 				data = record[2:]
 			} else {
 				data = record[1:]
 			}
 
-			start := NewBoard_BoolPacked(board_width, board_height)
-			end := NewBoard_BoolPacked(board_width, board_height)
+			start := NewBoard_BoolPacked(width, height)
+			end := NewBoard_BoolPacked(width, height)
 			if is_training {
-				start.LoadArray(data[0:400])
-				end.LoadArray(data[400:800])
+				start.LoadArray(data[0:cells])
+				end.LoadArray(data[cells:2*cells])
 			} else {
-				end.LoadArray(data[0:400])
+				end.LoadArray(data[0:cells])
 			}
 
 			s.problem[id] = LifeProblem{
@@ -382,9 +1154,10 @@ func (s *LifeProblemSet) load_csv_from_file(filename string, is_training bool, h
 			//fmt.Print(s.problem[id].start)
 		}
 		if id > id_max {
-			return // fact-of-life : ids are ascending order, so can quit reading early
+			return nil // fact-of-life : ids are ascending order, so can quit reading early
 		}
 	}
+	return nil
 }
 
 // Unlike the db, the ids here match the csv files exactly
@@ -430,11 +1203,17 @@ func determine_kaggle_score(fake_training_data_csv string, submission_csv string
 		id_list = append(id_list, id)
 	}
 	
-	training_data.load_csv_from_file(fake_training_data_csv, true, true, id_list)
+	if err := training_data.load_csv_from_file(fake_training_data_csv, true, true, id_list); err != nil {
+		fmt.Println("Error:", err)
+		return 0
+	}
 	//fmt.Println(training_data.problem[60001].start)
 	
 	// Mark is_training=false (only one block of data), and deny has_steps
-	submission.load_csv_from_file(submission_csv, false, false, id_list) 
+	if err := submission.load_csv_from_file(submission_csv, false, false, id_list); err != nil {
+		fmt.Println("Error:", err)
+		return 0
+	}
 	//fmt.Println(submission.problem[60001].end)
 
 	total_errors := 0
@@ -469,34 +1248,216 @@ func (s *LifeProblemSet) load_transition_collection(steps int) {
 }
 
 
+// StepHistogram counts the problems in the set for each steps value
+func StepHistogram(s *LifeProblemSet) map[int]int {
+	histogram := make(map[int]int)
+	for _, problem := range s.problem {
+		histogram[problem.steps]++
+	}
+	return histogram
+}
+
+// One neighbourhood code and how often it occurs (an alias, so it is interchangeable with the plain struct)
+type NeighborhoodCount = struct {
+	Code  uint16
+	Count int
+}
+
+// The 3x3 neighbourhood around (x,y), laid out as window_bit(), with off-board cells dead
+func neighborhood_code(b *Board_BoolPacked, x, y int) int {
+	code := 0
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if b.isSet_safe(x+dx, y+dy) {
+				code |= 1 << window_bit(dx, dy)
+			}
+		}
+	}
+	return code
+}
+
+// TopNeighborhoods counts the 3x3 neighbourhood codes (layout as window_bit()) around every cell of every 
+// board in the set (end if useEnd, otherwise start), and returns the n most common, most frequent first
+func TopNeighborhoods(s *LifeProblemSet, useEnd bool, n int) []NeighborhoodCount {
+	var counts [512]int
+	for _, problem := range s.problem {
+		b := problem.start
+		if useEnd {
+			b = problem.end
+		}
+		if b == nil {
+			continue
+		}
+		for y := 0; y < b.h; y++ {
+			for x := 0; x < b.w; x++ {
+				counts[neighborhood_code(b, x, y)]++
+			}
+		}
+	}
+
+	top := []NeighborhoodCount{}
+	for code, count := range counts {
+		if count > 0 {
+			top = append(top, NeighborhoodCount{Code: uint16(code), Count: count})
+		}
+	}
+	sort.SliceStable(top, func(i, j int) bool { // Ties stay in code order
+		return top[i].Count > top[j].Count
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// TransitionStatsBySteps gives, for each steps value and each 3x3 neighbourhood code seen on the end boards,
+// the fraction of those cells that were alive at the start.  Problems without a start (test data) are skipped
+func TransitionStatsBySteps(s *LifeProblemSet) map[int]map[uint16]float64 {
+	alive := make(map[int]map[uint16]int)
+	seen  := make(map[int]map[uint16]int)
+	for _, problem := range s.problem {
+		if problem.start == nil {
+			continue
+		}
+		if seen[problem.steps] == nil {
+			alive[problem.steps] = make(map[uint16]int)
+			seen[problem.steps]  = make(map[uint16]int)
+		}
+		for y := 0; y < problem.end.h; y++ {
+			for x := 0; x < problem.end.w; x++ {
+				code := uint16(neighborhood_code(problem.end, x, y))
+				seen[problem.steps][code]++
+				if problem.start.isSet(x, y) {
+					alive[problem.steps][code]++
+				}
+			}
+		}
+	}
+
+	stats := make(map[int]map[uint16]float64)
+	for steps, counts := range seen {
+		stats[steps] = make(map[uint16]float64)
+		for code, n := range counts {
+			stats[steps][code] = float64(alive[steps][code]) / float64(n)
+		}
+	}
+	return stats
+}
+
+// FindNearDuplicates groups the ids of problems whose boards (end if useEnd, otherwise start) 
+// are within maxHamming cells of each other (transitively).  Only groups of 2 or more are returned
+func FindNearDuplicates(s *LifeProblemSet, maxHamming int, useEnd bool) [][]int {
+	ids := []int{}
+	for id := range s.problem {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	board := func(id int) *Board_BoolPacked {
+		if useEnd {
+			return s.problem[id].end
+		}
+		return s.problem[id].start
+	}
+
+	// Populations differing by more than maxHamming can't be near each other : bucket by population
+	by_population := make(map[int][]int)
+	population := make(map[int]int)
+	for _, id := range ids {
+		if board(id) == nil {
+			continue
+		}
+		population[id] = board(id).Population()
+		by_population[population[id]] = append(by_population[population[id]], id)
+	}
+
+	// Union-find over the ids
+	parent := make(map[int]int)
+	var find func(id int) int
+	find = func(id int) int {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+	for id := range population {
+		parent[id] = id
+	}
+
+	for id, pop := range population {
+		for p := pop - maxHamming; p <= pop+maxHamming; p++ {
+			for _, other := range by_population[p] {
+				if other <= id || find(id) == find(other) {
+					continue
+				}
+				if board(id).CompareTo(board(other), nil) <= maxHamming {
+					parent[find(other)] = find(id)
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for _, id := range ids { // In id order, so each group comes out sorted
+		if _, ok := population[id]; ok {
+			groups[find(id)] = append(groups[find(id)], id)
+		}
+	}
+	result := [][]int{}
+	for _, group := range groups {
+		if len(group) > 1 {
+			result = append(result, group)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i][0] < result[j][0] })
+	return result
+}
+
 type ImageSet struct {
 	im                       *image.RGBA
 	rows, cols               int
+	w, h                     int // Size of each board in the grid
 	row_current, col_current int
 }
 
 func NewImageSet(rows, cols int) *ImageSet {
-	im := image.NewRGBA(image.Rect(0, 0, cols*(board_width+2)+2, rows*(board_height+2)+2))                             //*NRGBA (image.Image interface)
+	return NewImageSetSized(rows, cols, board_width, board_height)
+}
+
+// A grid of rows x cols boards, each w x h, with a 2 pixel gutter between them
+func NewImageSetSized(rows, cols, w, h int) *ImageSet {
+	im := image.NewRGBA(image.Rect(0, 0, cols*(w+2)+2, rows*(h+2)+2)) //*NRGBA (image.Image interface)
 	draw.Draw(im, im.Bounds(), image.NewUniform(color.RGBA{98, 166, 255, 255}), image.ZP, draw.Src) // color.Transparent
 	return &ImageSet{
 		im:   im,
 		rows: rows, cols: cols,
+		w: w, h: h,
 		row_current: 0, col_current: 0,
 	}
 }
 
-func (i *ImageSet) save(f string) {
-	w, _ := os.Create(f)
-	defer w.Close()
-	png.Encode(w, i.im)
+func (i *ImageSet) save(f string) error {
+	w, err := os.Create(f)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(w, i.im); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
 }
 
 func (i *ImageSet) DrawStats(row, col int, bs *BoardStats) {
-	offset_x := col*(board_width+2) + 2
-	offset_y := row*(board_height+2) + 2
+	offset_x := col*(i.w+2) + 2
+	offset_y := row*(i.h+2) + 2
+	if bs.count == 0 { // Nothing recorded : leave the tile as background
+		return
+	}
 
-	for x := 0; x < bs.w; x++ {
-		for y := 0; y < bs.h; y++ {
+	// Anything bigger than the grid's board size is clipped, rather than drawn over the neighbours
+	for x := 0; x < bs.w && x < i.w; x++ {
+		for y := 0; y < bs.h && y < i.h; y++ {
 			g := bs.freq[y][x] * 255 / bs.count
 			if bs.mismatch_amount>0 {
 				pct := 100 - bs.mismatch_amount * 50 / 100
@@ -511,6 +1472,33 @@ func (i *ImageSet) DrawStats(row, col int, bs *BoardStats) {
 	}
 }
 
+// DrawOverlay colours are as DrawStats (live white, dead black), with the changes picked out
+var overlay_both_color = color.RGBA{255, 255, 255, 255}
+var overlay_born_color = color.RGBA{0, 200, 0, 255}
+var overlay_died_color = color.RGBA{220, 0, 0, 255}
+var overlay_dead_color = color.RGBA{0, 0, 0, 255}
+
+// DrawOverlay shows start and end in one tile : alive in both, born (end only), died (start only), or dead in both
+func (i *ImageSet) DrawOverlay(row, col int, start, end *Board_BoolPacked) {
+	offset_x := col*(i.w+2) + 2
+	offset_y := row*(i.h+2) + 2
+
+	for x := 0; x < start.w && x < i.w; x++ {
+		for y := 0; y < start.h && y < i.h; y++ {
+			c := overlay_dead_color
+			switch was, is := start.isSet(x, y), end.isSet(x, y); {
+			case was && is:
+				c = overlay_both_color
+			case is:
+				c = overlay_born_color
+			case was:
+				c = overlay_died_color
+			}
+			i.im.Set(offset_x+x, offset_y+y, c)
+		}
+	}
+}
+
 func (i *ImageSet) DrawStatsNext(bs *BoardStats) {
 	i.DrawStats(i.row_current, i.col_current, bs)
 	i.col_current++
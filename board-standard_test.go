@@ -0,0 +1,1538 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"image/color"
+	"io/fs"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLoadCSVWithDelimiter(t *testing.T) {
+	var saved LifeProblemSet
+	saved.problem = map[int]LifeProblem{}
+	ids := []int{1, 2, 3}
+	for _, id := range ids {
+		saved.problem[id] = problem_from_start(id, random_board(board_width, board_height, 0.3, int64(id)), id)
+	}
+	dir := t.TempDir()
+	comma_csv := filepath.Join(dir, "comma.csv")
+	saved.save_csv(comma_csv)
+	data, err := os.ReadFile(comma_csv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want LifeProblemSet
+	want.load_csv_from_file(comma_csv, true, true, ids)
+
+	cases := []struct {
+		name    string
+		delim   rune
+		convert func(string) string
+	}{
+		{"comma", ',', func(s string) string { return s }},
+		{"tab", '\t', func(s string) string { return strings.ReplaceAll(s, ",", "\t") }},
+		{"semicolon", ';', func(s string) string { return strings.ReplaceAll(s, ",", ";") }},
+		{"quoted", ';', func(s string) string {
+			lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+			for i, line := range lines {
+				lines[i] = `"` + strings.ReplaceAll(line, ",", `";"`) + `"`
+			}
+			return strings.Join(lines, "\n") + "\n"
+		}},
+	}
+	for _, c := range cases {
+		path := filepath.Join(dir, c.name+".csv")
+		if err := os.WriteFile(path, []byte(c.convert(string(data))), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var got LifeProblemSet
+		got.LoadCSVWithDelimiter(path, c.delim, true, ids)
+		for _, id := range ids {
+			g, w := got.problem[id], want.problem[id]
+			if g.steps != w.steps || g.start.CompareTo(w.start, nil) != 0 || g.end.CompareTo(w.end, nil) != 0 {
+				t.Errorf("%s: problem[%d] differs from the comma-separated load", c.name, id)
+			}
+		}
+	}
+}
+
+func TestEdgePressure(t *testing.T) {
+	edge := strings.Repeat("-", board_width-1) + "X"
+	full := NewBoard_BoolPacked(board_width, board_height)
+	for y := 0; y < board_height; y++ {
+		for x := 0; x < board_width; x++ {
+			full.Set(x, y, true)
+		}
+	}
+	corners := board_from_rows(board_width, board_height, "X"+edge[1:])
+	corners.Set(0, board_height-1, true)
+	corners.Set(board_width-1, board_height-1, true)
+	mixed := board_from_rows(board_width, board_height, "-X---", "--X--", "X----", "---X-", edge[:board_width-5]+"----X")
+	cases := []struct {
+		name  string
+		board *Board_BoolPacked
+		want  int
+	}{
+		{"empty", NewBoard_BoolPacked(board_width, board_height), 0},
+		{"interior block", board_from_rows(board_width, board_height, "", "-XX", "-XX"), 0},
+		{"corners", corners, 4},
+		{"full board counts the whole ring", full, 2*board_width + 2*board_height - 4},
+		{"mixed", mixed, 3},
+	}
+	for _, c := range cases {
+		if got := c.board.EdgePressure(); got != c.want {
+			t.Errorf("%s: EdgePressure() = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestStringANSI(t *testing.T) {
+	// The expected plain rendering of a board_width x board_height board drawn from 'rows'
+	render := func(rows ...string) string {
+		var b strings.Builder
+		for y := 0; y < board_height; y++ {
+			for x := 0; x < board_width; x++ {
+				if y < len(rows) && x < len(rows[y]) && rows[y][x] == 'X' {
+					b.WriteString("\u2588")
+				} else {
+					b.WriteByte(' ')
+				}
+			}
+			b.WriteByte('\n')
+		}
+		return b.String()
+	}
+	cases := []struct {
+		name string
+		rows []string
+	}{
+		{"empty", nil},
+		{"blinker", []string{"", "XXX"}},
+		{"diagonal", []string{"X-", "-X"}},
+	}
+	for _, c := range cases {
+		board := board_from_rows(board_width, board_height, c.rows...)
+		plain := render(c.rows...)
+		if got := board.StringANSI(false); got != plain {
+			t.Errorf("%s: StringANSI(false) = %q, want %q", c.name, got, plain)
+		}
+		coloured := board.StringANSI(true)
+		live := strings.Count(strings.Join(c.rows, ""), "X")
+		if n := strings.Count(coloured, ansi_live_color); n != live {
+			t.Errorf("%s: %d colour escapes for %d live cells", c.name, n, live)
+		}
+		if n := strings.Count(coloured, ansi_reset); n != live {
+			t.Errorf("%s: %d resets for %d live cells", c.name, n, live)
+		}
+		stripped := strings.ReplaceAll(strings.ReplaceAll(coloured, ansi_live_color, ""), ansi_reset, "")
+		if stripped != plain {
+			t.Errorf("%s: colour output without escapes = %q, want %q", c.name, stripped, plain)
+		}
+	}
+}
+
+func TestPopulationDelta(t *testing.T) {
+	cases := []struct {
+		name    string
+		problem LifeProblem
+		want    int
+	}{
+		{"isolated cells die off", problem_from_start(1, board_from_rows(8, 8, "X", "", "", "----X", "", "", "-------X"), 1), -3},
+		{"block is stable", problem_from_start(2, board_from_rows(6, 6, "", "-XX", "-XX"), 5), 0},
+		{"tromino grows into a block", problem_from_start(3, board_from_rows(6, 6, "", "-XX", "-X"), 1), 1},
+		{"domino and a lone cell vanish", problem_from_start(4, board_from_rows(8, 8, "-XX", "", "", "", "-----X"), 3), -3},
+		{"test problem has no start", LifeProblem{id: 5, end: board_from_rows(4, 4, "XX")}, 0},
+	}
+	for _, c := range cases {
+		if got := c.problem.PopulationDelta(); got != c.want {
+			t.Errorf("%s: PopulationDelta() = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestQuadrants(t *testing.T) {
+	cases := []struct {
+		w, h int
+	}{
+		{20, 20}, {7, 5}, {6, 9}, {1, 3}, {2, 2},
+	}
+	for _, c := range cases {
+		b := random_board(c.w, c.h, 0.5, int64(c.w*100+c.h))
+		q := b.Quadrants()
+
+		// Extra row/column goes to the lower/right quadrants
+		left, top := c.w/2, c.h/2
+		sizes := [4][2]int{{left, top}, {c.w - left, top}, {left, c.h - top}, {c.w - left, c.h - top}}
+		offsets := [4][2]int{{0, 0}, {left, 0}, {0, top}, {left, top}}
+		for i := range q {
+			if q[i].w*q[i].h != 0 && (q[i].w != sizes[i][0] || q[i].h != sizes[i][1]) {
+				t.Errorf("%dx%d: quadrant %d is %dx%d, want %dx%d", c.w, c.h, i, q[i].w, q[i].h, sizes[i][0], sizes[i][1])
+			}
+		}
+
+		reassembled := NewBoard_BoolPacked(c.w, c.h)
+		for i := range q {
+			for y := 0; y < q[i].h; y++ {
+				for x := 0; x < q[i].w; x++ {
+					reassembled.Set(offsets[i][0]+x, offsets[i][1]+y, q[i].isSet(x, y))
+				}
+			}
+		}
+		if reassembled.CompareTo(b, nil) != 0 {
+			t.Errorf("%dx%d: reassembled quadrants differ from the original", c.w, c.h)
+		}
+	}
+}
+
+func TestToGoLiteral(t *testing.T) {
+	cases := []struct {
+		name  string
+		board *Board_BoolPacked
+	}{
+		{"empty", NewBoard_BoolPacked(4, 3)},
+		{"glider", board_from_rows(6, 6, "-X", "--X", "XXX")},
+		{"random", random_board(board_width, board_height, 0.4, 11)},
+		{"corners", board_from_rows(5, 5, "X---X", "", "", "", "X---X")},
+	}
+	header := regexp.MustCompile(`^b := NewBoard_BoolPacked\((\d+), (\d+)\)\nb\.SetCells\(\[\]\[2\]int\{(.*)\}\)\n$`)
+	coord := regexp.MustCompile(`\{(\d+), (\d+)\}`)
+	for _, c := range cases {
+		src := c.board.ToGoLiteral("b")
+		m := header.FindStringSubmatch(src)
+		if m == nil {
+			t.Errorf("%s: unexpected literal %q", c.name, src)
+			continue
+		}
+		w, _ := strconv.Atoi(m[1])
+		h, _ := strconv.Atoi(m[2])
+		cells := [][2]int{}
+		for _, xy := range coord.FindAllStringSubmatch(m[3], -1) {
+			x, _ := strconv.Atoi(xy[1])
+			y, _ := strconv.Atoi(xy[2])
+			cells = append(cells, [2]int{x, y})
+		}
+		rebuilt := NewBoard_BoolPacked(w, h)
+		rebuilt.SetCells(cells)
+		if w != c.board.w || h != c.board.h || rebuilt.CompareTo(c.board, nil) != 0 {
+			t.Errorf("%s: the emitted coordinates don't reproduce the board", c.name)
+		}
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	cases := []struct {
+		name   string
+		board  *Board_BoolPacked
+		cx, cy float64
+		ok     bool
+	}{
+		{"empty", NewBoard_BoolPacked(5, 5), 0, 0, false},
+		{"centred blinker", board_from_rows(5, 5, "", "", "-XXX"), 2, 2, true},
+		{"four corners", board_from_rows(6, 4, "X----X", "", "", "X----X"), 2.5, 1.5, true},
+		{"centred block on an even board", board_from_rows(4, 4, "", "-XX", "-XX"), 1.5, 1.5, true},
+		{"single cell", board_from_rows(8, 8, "", "", "", "-----X"), 5, 3, true},
+	}
+	for _, c := range cases {
+		cx, cy, ok := c.board.Centroid()
+		if cx != c.cx || cy != c.cy || ok != c.ok {
+			t.Errorf("%s: Centroid() = (%v, %v, %v), want (%v, %v, %v)", c.name, cx, cy, ok, c.cx, c.cy, c.ok)
+		}
+	}
+}
+
+func TestFromFlat(t *testing.T) {
+	// A 3x2 view into a 5-wide array : the 9s are outside the view and must be ignored
+	strided := []uint8{
+		1, 0, 2, 9, 9,
+		0, 7, 0, 9, 9,
+	}
+	cases := []struct {
+		name    string
+		w, h    int
+		data    []uint8
+		stride  int
+		want    *Board_BoolPacked
+		wantErr bool
+	}{
+		{"dense", 3, 2, []uint8{1, 0, 1, 0, 1, 0}, 3, board_from_rows(3, 2, "X-X", "-X-"), false},
+		{"strided view", 3, 2, strided, 5, board_from_rows(3, 2, "X-X", "-X-"), false},
+		{"view starting mid-row", 2, 2, strided[1:], 5, board_from_rows(2, 2, "-X", "X-"), false},
+		{"last row may be short", 3, 2, strided[:8], 5, board_from_rows(3, 2, "X-X", "-X-"), false},
+		{"too short", 3, 2, strided[:7], 5, nil, true},
+		{"stride below width", 3, 2, strided, 2, nil, true},
+		{"bad dimensions", 0, 2, strided, 5, nil, true},
+	}
+	for _, c := range cases {
+		got, err := FromFlat(c.w, c.h, c.data, c.stride)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got.CompareTo(c.want, nil) != 0 {
+			t.Errorf("%s: got\n%vwant\n%v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestComplexityScore(t *testing.T) {
+	// Scatters 'population' live cells at random over a w x h board
+	noise := func(w, h, population int, seed int64) *Board_BoolPacked {
+		b := NewBoard_BoolPacked(w, h)
+		r := rand.New(rand.NewSource(seed))
+		for b.Population() < population {
+			b.Set(r.Intn(w), r.Intn(h), true)
+		}
+		return b
+	}
+	cases := []struct {
+		name            string
+		simple, complex *Board_BoolPacked
+	}{
+		{"block vs noise", board_from_rows(board_width, board_height, "", "-XX", "-XX"), noise(board_width, board_height, 4, 1)},
+		{"filled rows vs noise", board_from_rows(board_width, board_height, "", "", "XXXXXXXXXXXXXXXXXXXX", "XXXXXXXXXXXXXXXXXXXX"), noise(board_width, board_height, 40, 2)},
+		{"empty vs a glider", NewBoard_BoolPacked(board_width, board_height), board_from_rows(board_width, board_height, "-X", "--X", "XXX")},
+	}
+	for _, c := range cases {
+		if s, x := c.simple.ComplexityScore(), c.complex.ComplexityScore(); s >= x {
+			t.Errorf("%s: scores %d and %d, want the first smaller", c.name, s, x)
+		}
+	}
+}
+
+func TestFullTrajectory(t *testing.T) {
+	glider := board_from_rows(10, 10, "-X", "--X", "XXX")
+	cases := []struct {
+		name    string
+		problem LifeProblem
+		start   *Board_BoolPacked
+		solved  bool
+	}{
+		{"solved glider", problem_from_start(1, glider, 4), glider, true},
+		{"solved soup", problem_from_start(2, random_board(board_width, board_height, 0.4, 4), 3), nil, true},
+		{"zero steps", problem_from_start(3, glider, 0), glider, true},
+		{"wrong start", problem_from_start(4, glider, 2), board_from_rows(10, 10, "", "-XX", "-XX"), false},
+	}
+	for _, c := range cases {
+		start := c.start
+		if start == nil {
+			start = c.problem.start
+		}
+		trajectory := c.problem.FullTrajectory(start)
+		if len(trajectory) != c.problem.steps+1 {
+			t.Fatalf("%s: %d boards, want %d", c.name, len(trajectory), c.problem.steps+1)
+		}
+		if trajectory[0].CompareTo(start, nil) != 0 {
+			t.Errorf("%s: trajectory doesn't begin with the start", c.name)
+		}
+		for i := 1; i < len(trajectory); i++ {
+			if trajectory[i].CompareTo(forward(start, i), nil) != 0 {
+				t.Errorf("%s: board %d isn't generation %d", c.name, i, i)
+			}
+		}
+		if solved := trajectory[len(trajectory)-1].CompareTo(c.problem.end, nil) == 0; solved != c.solved {
+			t.Errorf("%s: last board matches the end = %v, want %v", c.name, solved, c.solved)
+		}
+	}
+}
+
+func TestFindNearDuplicates(t *testing.T) {
+	base := random_board(board_width, board_height, 0.3, 1)
+	flipped := func(cells ...[2]int) *Board_BoolPacked {
+		b := NewBoard_BoolPacked(base.w, base.h)
+		b.CopyFrom(base)
+		for _, c := range cells {
+			b.Set(c[0], c[1], !b.isSet(c[0], c[1]))
+		}
+		return b
+	}
+	s := &LifeProblemSet{problem: map[int]LifeProblem{
+		1: {id: 1, end: base},
+		2: {id: 2, end: random_board(board_width, board_height, 0.3, 2)},
+		3: {id: 3, end: flipped([2]int{4, 4})},
+		4: {id: 4, end: flipped([2]int{4, 4}, [2]int{5, 5})},
+		5: {id: 5, end: flipped([2]int{0, 0}, [2]int{1, 0}, [2]int{2, 0}, [2]int{3, 0})},
+	}}
+	cases := []struct {
+		name       string
+		maxHamming int
+		want       [][]int
+	}{
+		{"exact duplicates only", 0, [][]int{}},
+		{"one cell apart", 1, [][]int{{1, 3, 4}}},
+		{"four cells apart", 4, [][]int{{1, 3, 4, 5}}},
+	}
+	for _, c := range cases {
+		if got := FindNearDuplicates(s, c.maxHamming, true); fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("%s: FindNearDuplicates() = %v, want %v", c.name, got, c.want)
+		}
+	}
+	// Test problems have no start to compare
+	if got := FindNearDuplicates(s, 4, false); len(got) != 0 {
+		t.Errorf("starts of test problems: got %v, want no groups", got)
+	}
+}
+
+func TestNextCellState(t *testing.T) {
+	six := board_from_rows(5, 5, "", "-XXX", "-X-X", "-X")        // Dead centre (2,2) with 6 live neighbours
+	three := board_from_rows(5, 5, "", "-XX", "--X")              // Dead centre (2,2) with 3
+	corner := board_from_rows(5, 5, "----X", "", "", "", "X---X") // (0,0) only has neighbours across the edges
+	cases := []struct {
+		name     string
+		board    *Board_BoolPacked
+		x, y     int
+		rule     Rule
+		boundary BoundaryMode
+		want     bool
+	}{
+		{"B3 births on three", three, 2, 2, RuleConway, BoundaryDead, true},
+		{"HighLife births on three", three, 2, 2, RuleHighLife, BoundaryDead, true},
+		{"Conway doesn't birth on six", six, 2, 2, RuleConway, BoundaryDead, false},
+		{"HighLife births on six", six, 2, 2, RuleHighLife, BoundaryDead, true},
+		{"live cell with six dies under HighLife", board_from_rows(3, 3, "XX-", "XXX", "-XX"), 1, 1, RuleHighLife, BoundaryDead, false},
+		{"dead boundary", corner, 0, 0, RuleConway, BoundaryDead, false},
+		{"wrapped boundary", corner, 0, 0, RuleConway, BoundaryWrap, true},
+	}
+	for _, c := range cases {
+		if got := c.board.NextCellState(c.x, c.y, c.rule, c.boundary); got != c.want {
+			t.Errorf("%s: NextCellState(%d,%d) = %v, want %v", c.name, c.x, c.y, got, c.want)
+		}
+	}
+
+	// IterateCell is the Conway/dead-boundary default
+	b := random_board(board_width, board_height, 0.4, 3)
+	for y := 0; y < b.h; y++ {
+		for x := 0; x < b.w; x++ {
+			if b.IterateCell(x, y) != b.NextCellState(x, y, RuleConway, BoundaryDead) {
+				t.Fatalf("IterateCell(%d,%d) differs from NextCellState", x, y)
+			}
+		}
+	}
+}
+
+func TestImageSetNonSquare(t *testing.T) {
+	cases := []struct {
+		rows, cols, w, h int
+	}{
+		{3, 4, 20, 30},
+		{2, 5, 30, 20},
+		{1, 1, 7, 3},
+	}
+	for _, c := range cases {
+		set := NewImageSetSized(c.rows, c.cols, c.w, c.h)
+		if size := set.im.Bounds().Size(); size.X != c.cols*(c.w+2)+2 || size.Y != c.rows*(c.h+2)+2 {
+			t.Fatalf("%dx%d boards : image is %v", c.w, c.h, size)
+		}
+		background := set.im.At(0, 0)
+
+		// Tile k is all alive, except for cell (k%w, k/w), so misplaced tiles show up
+		for k := 0; k < c.rows*c.cols; k++ {
+			b := random_board(c.w, c.h, 1, 0)
+			b.Set(k%c.w, k/c.w, false)
+			bs := NewBoardStats(c.w, c.h)
+			b.AddToStats(bs)
+			set.DrawStatsNext(bs)
+		}
+
+		for py := 0; py < set.im.Bounds().Dy(); py++ {
+			for px := 0; px < set.im.Bounds().Dx(); px++ {
+				col, x := (px-2)/(c.w+2), (px-2)%(c.w+2)
+				row, y := (py-2)/(c.h+2), (py-2)%(c.h+2)
+				want := background
+				if px >= 2 && py >= 2 && x < c.w && y < c.h {
+					want = color.Gray{255}
+					if k := row*c.cols + col; x == k%c.w && y == k/c.w {
+						want = color.Gray{0}
+					}
+				}
+				if !same_color(set.im.At(px, py), want) {
+					t.Fatalf("%dx%d boards : pixel (%d,%d) is %v, want %v", c.w, c.h, px, py, set.im.At(px, py), want)
+				}
+			}
+		}
+	}
+}
+
+func TestNormalizePhase(t *testing.T) {
+	horizontal := board_from_rows(5, 5, "", "", "-XXX")
+	vertical := board_from_rows(5, 5, "", "--X", "--X", "--X")
+	toad := board_from_rows(6, 6, "", "", "--XXX", "-XXX")
+	block := board_from_rows(4, 4, "", "-XX", "-XX")
+	glider := board_from_rows(10, 10, "-X", "--X", "XXX")
+	cases := []struct {
+		name      string
+		board     *Board_BoolPacked
+		canonical *Board_BoolPacked
+		offset    int
+	}{
+		// "0000000000000000000001110..." (horizontal) < "0000000100..." (vertical)
+		{"horizontal blinker", horizontal, horizontal, 0},
+		{"vertical blinker", vertical, horizontal, 1},
+		{"toad phase 0", toad, toad, 0},
+		{"toad phase 1", forward(toad, 1), toad, 1},
+		{"still life", block, block, 0},
+		{"not an oscillator", glider, glider, 0},
+	}
+	for _, c := range cases {
+		canonical, offset := NormalizePhase(c.board, 10)
+		if canonical.CompareTo(c.canonical, nil) != 0 || offset != c.offset {
+			t.Errorf("%s: NormalizePhase() gave offset %d and\n%v, want offset %d and\n%v", c.name, offset, canonical, c.offset, c.canonical)
+		}
+		if forward(c.board, offset).CompareTo(canonical, nil) != 0 {
+			t.Errorf("%s: canonical phase isn't %d steps on", c.name, offset)
+		}
+	}
+}
+
+func TestStepHistogram(t *testing.T) {
+	set := func(steps ...int) *LifeProblemSet {
+		s := &LifeProblemSet{problem: map[int]LifeProblem{}}
+		for i, n := range steps {
+			s.problem[i+1] = LifeProblem{id: i + 1, end: NewBoard_BoolPacked(4, 4), steps: n}
+		}
+		return s
+	}
+	cases := []struct {
+		name string
+		set  *LifeProblemSet
+		want map[int]int
+	}{
+		{"empty", set(), map[int]int{}},
+		{"one of each", set(1, 2, 3, 4, 5), map[int]int{1: 1, 2: 1, 3: 1, 4: 1, 5: 1}},
+		{"skewed", set(1, 1, 1, 5, 2, 5, 1), map[int]int{1: 4, 2: 1, 5: 2}},
+	}
+	for _, c := range cases {
+		if got := StepHistogram(c.set); fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("%s: StepHistogram() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestMergeByConfidence(t *testing.T) {
+	a := random_board(board_width, board_height, 0.5, 1)
+	b := random_board(board_width, board_height, 0.5, 2)
+	conf := func(value func(x, y int) float64) [][]float64 {
+		c := make([][]float64, board_height)
+		for y := range c {
+			c[y] = make([]float64, board_width)
+			for x := range c[y] {
+				c[y][x] = value(x, y)
+			}
+		}
+		return c
+	}
+	left := func(x, y int) float64 {
+		if x < board_width/2 {
+			return 0.9
+		}
+		return 0.2
+	}
+	right := func(x, y int) float64 { return 1.1 - left(x, y) }
+	flat := func(x, y int) float64 { return 0.5 }
+
+	// spliced takes the left half from a and the right half from b
+	spliced := NewBoard_BoolPacked(board_width, board_height)
+	spliced.CopyFrom(b)
+	for y := 0; y < board_height; y++ {
+		for x := 0; x < board_width/2; x++ {
+			spliced.Set(x, y, a.isSet(x, y))
+		}
+	}
+
+	cases := []struct {
+		name         string
+		confA, confB [][]float64
+		want         *Board_BoolPacked
+	}{
+		{"a left, b right", conf(left), conf(right), spliced},
+		{"a everywhere", conf(func(x, y int) float64 { return 1 }), conf(flat), a},
+		{"b everywhere", conf(flat), conf(func(x, y int) float64 { return 0.51 }), b},
+		{"ties go to a", conf(flat), conf(flat), a},
+	}
+	for _, c := range cases {
+		if got := MergeByConfidence(a, b, c.confA, c.confB); got.CompareTo(c.want, nil) != 0 {
+			t.Errorf("%s: merged board differs in %d cells", c.name, got.CompareTo(c.want, nil))
+		}
+	}
+}
+
+func TestAutocorrelation(t *testing.T) {
+	pattern := func(alive func(x, y int) bool) *Board_BoolPacked {
+		b := NewBoard_BoolPacked(board_width, board_height)
+		for y := 0; y < b.h; y++ {
+			for x := 0; x < b.w; x++ {
+				b.Set(x, y, alive(x, y))
+			}
+		}
+		return b
+	}
+	const maxLag = 8
+	cases := []struct {
+		name  string
+		board *Board_BoolPacked
+		peak  func(dx, dy int) bool // Lags where the pattern repeats exactly
+	}{
+		{"vertical stripes every 4", pattern(func(x, y int) bool { return x%4 == 0 }),
+			func(dx, dy int) bool { return dx%4 == 0 }},
+		{"horizontal stripes every 5", pattern(func(x, y int) bool { return y%5 < 2 }),
+			func(dx, dy int) bool { return dy%5 == 0 }},
+		{"checkerboard", pattern(func(x, y int) bool { return (x+y)%2 == 0 }),
+			func(dx, dy int) bool { return (dx+dy)%2 == 0 }},
+	}
+	for _, c := range cases {
+		corr := c.board.Autocorrelation(maxLag)
+		for dy := -maxLag; dy <= maxLag; dy++ {
+			for dx := -maxLag; dx <= maxLag; dx++ {
+				got := corr[dy+maxLag][dx+maxLag]
+				if c.peak(dx, dy) && math.Abs(got-1) > 1e-9 {
+					t.Errorf("%s: lag (%d,%d) is %v, want a peak of 1", c.name, dx, dy, got)
+				}
+				if !c.peak(dx, dy) && got > 0.5 {
+					t.Errorf("%s: lag (%d,%d) is %v, want well below the peaks", c.name, dx, dy, got)
+				}
+			}
+		}
+	}
+
+	for _, flat := range []*Board_BoolPacked{NewBoard_BoolPacked(6, 6), random_board(6, 6, 1, 0)} {
+		for _, row := range flat.Autocorrelation(2) {
+			for _, v := range row {
+				if v != 0 {
+					t.Fatalf("a board with no variance gave %v", v)
+				}
+			}
+		}
+	}
+}
+
+func TestOscillatorPhases(t *testing.T) {
+	pulsar := NewBoard_BoolPacked(board_width, board_height)
+	for _, r := range []int{0, 5, 7, 12} {
+		for _, c := range []int{2, 3, 4, 8, 9, 10} {
+			pulsar.Set(3+c, 3+r, true)
+			pulsar.Set(3+r, 3+c, true)
+		}
+	}
+	cases := []struct {
+		name   string
+		board  *Board_BoolPacked
+		ok     bool
+		phases int
+	}{
+		{"pulsar", pulsar, true, 3},
+		{"blinker", board_from_rows(5, 5, "", "", "-XXX-"), true, 2},
+		{"block", board_from_rows(4, 4, "", "-XX-", "-XX-"), true, 1},
+		{"empty", NewBoard_BoolPacked(6, 6), true, 1},
+		{"glider", board_from_rows(board_width, board_height, "-X-", "--X", "XXX"), false, 0},
+		{"r-pentomino", board_from_rows(board_width, board_height, "", "", "", "", "", "", "", "", "", "--------XX", "-------XX", "--------X"), false, 0},
+	}
+	for _, c := range cases {
+		phases, ok := OscillatorPhases(c.board, 10)
+		if ok != c.ok || len(phases) != c.phases {
+			t.Errorf("%s: got ok=%v with %d phases, want ok=%v with %d", c.name, ok, len(phases), c.ok, c.phases)
+			continue
+		}
+		for i, phase := range phases {
+			if want := forward(c.board, i); phase.CompareTo(want, nil) != 0 {
+				t.Errorf("%s: phase %d is not the board %d steps on", c.name, i, i)
+			}
+			for j := 0; j < i; j++ {
+				if phase.CompareTo(phases[j], nil) == 0 {
+					t.Errorf("%s: phases %d and %d are the same", c.name, j, i)
+				}
+			}
+		}
+	}
+}
+
+func TestDumpProblemAsTest(t *testing.T) {
+	start := random_board(board_width, board_height, 0.3, 7)
+	training := problem_from_start(77, start, 2)
+	blank := training
+	blank.id, blank.start = 78, nil
+
+	cases := []struct {
+		name      string
+		problem   LifeProblem
+		predicted *Board_BoolPacked
+	}{
+		{"wrong prediction", training, training.end},
+		{"exact prediction", training, start},
+		{"test problem", blank, NewBoard_BoolPacked(board_width, board_height)},
+	}
+	for _, c := range cases {
+		path := filepath.Join(t.TempDir(), "problem_test.go")
+		if err := DumpProblemAsTest(c.problem, c.predicted, path); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			t.Fatalf("%s: generated file does not parse : %v", c.name, err)
+		}
+		if want := fmt.Sprintf("TestProblem%d", c.problem.id); len(file.Decls) == 0 || file.Scope.Lookup(want) == nil {
+			t.Errorf("%s: generated file does not declare %s", c.name, want)
+		}
+		_, mismatch := c.problem.Verify(c.predicted)
+		source, _ := os.ReadFile(path)
+		if want := fmt.Sprintf("if mismatch != %d {", mismatch); !strings.Contains(string(source), want) {
+			t.Errorf("%s: generated file does not assert %q", c.name, want)
+		}
+	}
+}
+
+func TestTopNeighborhoods(t *testing.T) {
+	dot3, dot5 := board_from_rows(3, 3, "", "-X-"), board_from_rows(5, 5, "", "", "--X--")
+	empty5, full3 := NewBoard_BoolPacked(5, 5), board_from_rows(3, 3, "XXX", "XXX", "XXX")
+	set := func(problems ...LifeProblem) *LifeProblemSet {
+		s := &LifeProblemSet{problem: map[int]LifeProblem{}}
+		for i, problem := range problems {
+			s.problem[i] = problem
+		}
+		return s
+	}
+
+	cases := []struct {
+		name   string
+		set    *LifeProblemSet
+		useEnd bool
+		n      int
+		length int
+		top    NeighborhoodCount // The most common code
+	}{
+		// Each of the 9 cells sees the dot in a different place, on both boards : a 9-way tie, in code order
+		{"two dots", set(LifeProblem{end: dot3}, LifeProblem{end: dot3}), true, 100, 9, NeighborhoodCount{Code: 1, Count: 2}},
+		{"two dots, top 2", set(LifeProblem{end: dot3}, LifeProblem{end: dot3}), true, 2, 2, NeighborhoodCount{Code: 1, Count: 2}},
+		// 16 cells around the dot, and all of the empty board, see nothing
+		{"dot and empty", set(LifeProblem{end: dot5}, LifeProblem{end: empty5}), true, 3, 3, NeighborhoodCount{Code: 0, Count: 16 + 25}},
+		{"full corner cells", set(LifeProblem{end: full3}), true, 1, 1, NeighborhoodCount{Code: 1<<0 | 1<<1 | 1<<3 | 1<<4, Count: 1}},
+		{"starts", set(LifeProblem{start: empty5, end: dot3}), false, 5, 1, NeighborhoodCount{Code: 0, Count: 25}},
+		{"no starts", set(LifeProblem{end: dot3}), false, 5, 0, NeighborhoodCount{}},
+	}
+	for _, c := range cases {
+		top := TopNeighborhoods(c.set, c.useEnd, c.n)
+		if len(top) != c.length {
+			t.Errorf("%s: got %d codes, want %d", c.name, len(top), c.length)
+			continue
+		}
+		if len(top) > 0 && top[0] != c.top {
+			t.Errorf("%s: most common is %+v, want %+v", c.name, top[0], c.top)
+		}
+		for i := 1; i < len(top); i++ {
+			if top[i-1].Count < top[i].Count {
+				t.Errorf("%s: not sorted by count : %+v", c.name, top)
+			}
+		}
+	}
+}
+
+func TestLoadRLE(t *testing.T) {
+	const pentadecathlon = "#N Pentadecathlon\n#C Period 15\nx = 10, y = 3, rule = B3/S23\n2bo4bo2b$2ob4ob2o$2bo4bo!\n"
+	cases := []struct {
+		name   string
+		rle    string
+		w, h   int // Board size to load into (0x0 : take the pattern's)
+		cells  int
+		period int // 0 : not checked
+		err    bool
+	}{
+		{"pentadecathlon", pentadecathlon, board_width, board_height, 12, 15, false},
+		{"blinker", "x = 3, y = 1\n3o!", 5, 5, 3, 2, false},
+		{"block, sized to fit", "x = 2, y = 2\n2o$2o!", 0, 0, 4, 1, false},
+		{"glider over lines", "#C comment\nx = 3, y = 3, rule = B3/S23\nbo$\n2bo$3o\n!", board_width, board_height, 5, 0, false},
+		{"blank rows", "x = 3, y = 5\no2$o2$o!", 0, 0, 3, 0, false},
+		{"too big for the board", pentadecathlon, 5, 5, 0, 0, true},
+		{"no header", "3o!", board_width, board_height, 0, 0, true},
+		{"no terminator", "x = 3, y = 3\n3o", board_width, board_height, 0, 0, true},
+		{"run past the header size", "x = 2, y = 1\n3o!", board_width, board_height, 0, 0, true},
+		{"bad character", "x = 3, y = 1\no*o!", board_width, board_height, 0, 0, true},
+		{"bad header", "x = three, y = 1\n3o!", board_width, board_height, 0, 0, true},
+	}
+	for _, c := range cases {
+		b := &Board_BoolPacked{}
+		if c.w > 0 {
+			b = NewBoard_BoolPacked(c.w, c.h)
+		}
+		err := b.LoadRLE(strings.NewReader(c.rle))
+		if (err != nil) != c.err {
+			t.Errorf("%s: error %v, want error=%v", c.name, err, c.err)
+			continue
+		}
+		if c.err {
+			continue
+		}
+		if b.Population() != c.cells {
+			t.Errorf("%s: %d live cells, want %d", c.name, b.Population(), c.cells)
+		}
+		if c.period > 0 {
+			l := NewBoardIterator(b.w, b.h)
+			l.current.CopyFrom(b)
+			for i := 1; i <= c.period; i++ {
+				l.Iterate(1)
+				if back := l.current.CompareTo(b, nil) == 0; back != (i == c.period) {
+					t.Errorf("%s: back to the start after %d generations is %v, want period %d", c.name, i, back, c.period)
+				}
+			}
+		}
+	}
+}
+
+func TestWriteRLERoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		original := random_board(1+r.Intn(30), 1+r.Intn(30), r.Float32(), int64(i))
+		var buf strings.Builder
+		if err := original.WriteRLE(&buf); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if len(line) > 70 {
+				t.Fatalf("%dx%d board : RLE line of %d characters", original.w, original.h, len(line))
+			}
+		}
+		loaded := &Board_BoolPacked{}
+		if err := loaded.LoadRLE(strings.NewReader(buf.String())); err != nil {
+			t.Fatalf("%dx%d board : %v", original.w, original.h, err)
+		}
+		if loaded.w != original.w || loaded.h != original.h || loaded.CompareTo(original, nil) != 0 {
+			t.Fatalf("%dx%d board does not survive WriteRLE/LoadRLE", original.w, original.h)
+		}
+	}
+}
+
+// reference_step is a plain cell-by-cell Conway step with a dead boundary, using only b's own size
+func reference_step(b *Board_BoolPacked) *Board_BoolPacked {
+	next := NewBoard_BoolPacked(b.w, b.h)
+	for y := 0; y < b.h; y++ {
+		for x := 0; x < b.w; x++ {
+			alive := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if (dx != 0 || dy != 0) && nx >= 0 && nx < b.w && ny >= 0 && ny < b.h && b.isSet(nx, ny) {
+						alive++
+					}
+				}
+			}
+			next.Set(x, y, alive == 3 || alive == 2 && b.isSet(x, y))
+		}
+	}
+	return next
+}
+
+// Boards of different sizes, stepped in lock-step in one test, each only ever use their own dimensions
+func TestPerInstanceDimensions(t *testing.T) {
+	cases := []struct {
+		name string
+		b    *Board_BoolPacked
+	}{
+		{"5x5 blinker", board_from_rows(5, 5, "", "", "-XXX-")},
+		{"20x20 soup", random_board(20, 20, 0.3, 1)},
+		{"25x22 soup", random_board(25, 22, 0.5, 2)},
+		{"3x7 soup", random_board(3, 7, 0.6, 3)},
+		{"30x1 row", random_board(30, 1, 0.8, 4)},
+	}
+	iterators := make([]*BoardIterator, len(cases))
+	want := make([]*Board_BoolPacked, len(cases))
+	for i, c := range cases {
+		iterators[i] = NewBoardIterator(c.b.w, c.b.h)
+		iterators[i].current.CopyFrom(c.b)
+		want[i] = NewBoard_BoolPacked(c.b.w, c.b.h)
+		want[i].CopyFrom(c.b)
+	}
+	for step := 1; step <= 6; step++ {
+		for i, c := range cases {
+			iterators[i].Iterate(1)
+			want[i] = reference_step(want[i])
+			if iterators[i].current.CompareTo(want[i], nil) != 0 {
+				t.Fatalf("%s: differs from the reference after %d steps", c.name, step)
+			}
+		}
+	}
+
+	for _, c := range cases {
+		generic := NewBoard_BoolPacked(c.b.w, c.b.h)
+		c.b.Iterate_Generic(generic)
+		if generic.CompareTo(reference_step(c.b), nil) != 0 {
+			t.Errorf("%s: Iterate_Generic differs from the reference", c.name)
+		}
+
+		// String() draws a one-cell frame around the board
+		lines := strings.Split(strings.TrimSuffix(c.b.String(), "\n"), "\n")
+		if len(lines) != c.b.h+2 || len(lines[0]) != c.b.w+2 {
+			t.Errorf("%s: String() is %d lines of %d, want %d of %d", c.name, len(lines), len(lines[0]), c.b.h+2, c.b.w+2)
+		}
+	}
+}
+
+// load_csv_from_file_sized reads boards of the size it is given, not the CLI's default
+func TestLoadCSVSized(t *testing.T) {
+	cases := []struct{ w, h int }{{5, 5}, {3, 7}, {25, 22}}
+	for _, c := range cases {
+		start, end := random_board(c.w, c.h, 0.4, 1), random_board(c.w, c.h, 0.4, 2)
+		header, row := "id,delta", "7,1"
+		for i := 1; i <= c.w*c.h; i++ {
+			header += fmt.Sprintf(",start.%d", i)
+		}
+		for i := 1; i <= c.w*c.h; i++ {
+			header += fmt.Sprintf(",stop.%d", i)
+		}
+		for _, b := range []*Board_BoolPacked{start, end} {
+			row += b.toCSV()
+		}
+		path := filepath.Join(t.TempDir(), "sized.csv")
+		if err := os.WriteFile(path, []byte(header+"\n"+row+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var s LifeProblemSet
+		s.load_csv_from_file_sized(path, ',', true, true, []int{7}, c.w, c.h)
+		p := s.problem[7]
+		if p.start.w != c.w || p.start.h != c.h || p.start.CompareTo(start, nil) != 0 || p.end.CompareTo(end, nil) != 0 {
+			t.Errorf("%dx%d: boards did not load at their own size", c.w, c.h)
+		}
+	}
+}
+
+func TestActivityMask(t *testing.T) {
+	block_and_blinker := board_from_rows(12, 12, "", "-XX", "-XX", "", "", "", "-------XXX")
+	blinker_cells := [][2]int{{7, 6}, {9, 6}, {8, 5}, {8, 7}} // The centre cell never changes
+	cases := []struct {
+		name   string
+		start  *Board_BoolPacked
+		steps  int
+		active [][2]int // nil : only checked against the trajectory
+	}{
+		{"block and blinker", block_and_blinker, 4, blinker_cells},
+		{"block and blinker, one step", block_and_blinker, 1, blinker_cells},
+		{"no steps", block_and_blinker, 0, [][2]int{}},
+		{"block", board_from_rows(6, 6, "", "-XX", "-XX"), 10, [][2]int{}},
+		{"lone cell dies", board_from_rows(5, 5, "", "", "--X"), 3, [][2]int{{2, 2}}},
+		{"glider", board_from_rows(board_width, board_height, "-X", "--X", "XXX"), 8, nil},
+		{"soup", random_board(board_width, board_height, 0.4, 1), 5, nil},
+	}
+	for _, c := range cases {
+		mask := ActivityMask(c.start, c.steps)
+
+		// Every cell that differs between consecutive generations, and nothing else
+		want := NewBoard_BoolPacked(c.start.w, c.start.h)
+		for i := 0; i < c.steps; i++ {
+			a, b := forward(c.start, i), forward(c.start, i+1)
+			for y := 0; y < want.h; y++ {
+				for x := 0; x < want.w; x++ {
+					if a.isSet(x, y) != b.isSet(x, y) {
+						want.Set(x, y, true)
+					}
+				}
+			}
+		}
+		if mask.CompareTo(want, nil) != 0 {
+			t.Errorf("%s: mask differs from the trajectory's changes by %d cells", c.name, mask.CompareTo(want, nil))
+		}
+		if c.active != nil {
+			explicit := NewBoard_BoolPacked(c.start.w, c.start.h)
+			explicit.SetCells(c.active)
+			if mask.CompareTo(explicit, nil) != 0 {
+				t.Errorf("%s: got mask\n%s", c.name, mask)
+			}
+		}
+	}
+}
+
+func TestRotationsAndFlips(t *testing.T) {
+	cases := []struct {
+		name      string
+		transform func(*Board_BoolPacked) *Board_BoolPacked
+		inverse   func(*Board_BoolPacked) *Board_BoolPacked
+		swaps     bool   // Width and height change places
+		corner    [2]int // Where (0,0) of a 3x2 board ends up
+	}{
+		{"Rotate90", (*Board_BoolPacked).Rotate90, (*Board_BoolPacked).Rotate270, true, [2]int{1, 0}},
+		{"Rotate180", (*Board_BoolPacked).Rotate180, (*Board_BoolPacked).Rotate180, false, [2]int{2, 1}},
+		{"Rotate270", (*Board_BoolPacked).Rotate270, (*Board_BoolPacked).Rotate90, true, [2]int{0, 2}},
+		{"FlipH", (*Board_BoolPacked).FlipH, (*Board_BoolPacked).FlipH, false, [2]int{2, 0}},
+		{"FlipV", (*Board_BoolPacked).FlipV, (*Board_BoolPacked).FlipV, false, [2]int{0, 1}},
+	}
+	boards := []*Board_BoolPacked{
+		random_board(14, 9, 0.4, 1),
+		random_board(board_width, board_height, 0.3, 2),
+		random_board(5, 17, 0.5, 3),
+	}
+	for _, c := range cases {
+		corner := c.transform(board_from_rows(3, 2, "X"))
+		if corner.Population() != 1 || !corner.isSet(c.corner[0], c.corner[1]) {
+			t.Errorf("%s: the corner cell moved to the wrong place\n%s", c.name, corner)
+		}
+
+		for _, b := range boards {
+			transformed := c.transform(b)
+			w, h := b.w, b.h
+			if c.swaps {
+				w, h = h, w
+			}
+			if transformed.w != w || transformed.h != h {
+				t.Errorf("%s: %dx%d board became %dx%d, want %dx%d", c.name, b.w, b.h, transformed.w, transformed.h, w, h)
+			}
+			if transformed.Population() != b.Population() {
+				t.Errorf("%s: population changed", c.name)
+			}
+			if c.inverse(transformed).CompareTo(b, nil) != 0 {
+				t.Errorf("%s: undoing the transform does not give back the %dx%d board", c.name, b.w, b.h)
+			}
+
+			// Life is invariant under the symmetries : stepping commutes with transforming
+			for steps := 1; steps <= 3; steps++ {
+				if forward(transformed, steps).CompareTo(c.transform(forward(b, steps)), nil) != 0 {
+					t.Errorf("%s: %dx%d board, transforming and %d steps do not commute", c.name, b.w, b.h, steps)
+				}
+			}
+		}
+	}
+
+	b := boards[0]
+	if b.Rotate90().Rotate90().CompareTo(b.Rotate180(), nil) != 0 || b.FlipH().FlipV().CompareTo(b.Rotate180(), nil) != 0 {
+		t.Error("the transforms do not compose as a group")
+	}
+}
+
+func TestTransitionStatsBySteps(t *testing.T) {
+	full, empty := random_board(4, 4, 1, 0), NewBoard_BoolPacked(4, 4)
+	dot := board_from_rows(3, 3, "", "-X-")
+	set := func(problems ...LifeProblem) *LifeProblemSet {
+		s := &LifeProblemSet{problem: map[int]LifeProblem{}}
+		for i, problem := range problems {
+			problem.id = i
+			s.problem[i] = problem
+		}
+		return s
+	}
+	cases := []struct {
+		name string
+		set  *LifeProblemSet
+		want map[int]map[uint16]float64
+	}{
+		// The same (empty) end, but everything alive before 1 step and nothing before 2 : the steps keep them apart
+		{"separated by steps",
+			set(LifeProblem{steps: 1, start: full, end: empty}, LifeProblem{steps: 2, start: empty, end: empty}),
+			map[int]map[uint16]float64{1: {0: 1}, 2: {0: 0}}},
+		{"pooled within steps",
+			set(LifeProblem{steps: 1, start: full, end: empty}, LifeProblem{steps: 1, start: empty, end: empty}),
+			map[int]map[uint16]float64{1: {0: 0.5}}},
+		{"test problems skipped",
+			set(LifeProblem{steps: 1, start: full, end: empty}, LifeProblem{steps: 3, end: empty}),
+			map[int]map[uint16]float64{1: {0: 1}}},
+		// Each cell sees the dot at a different place in its window, and only the dot itself was alive
+		{"per code",
+			set(LifeProblem{steps: 1, start: dot, end: dot}),
+			map[int]map[uint16]float64{1: {1: 0, 2: 0, 4: 0, 8: 0, window_center: 1, 32: 0, 64: 0, 128: 0, 256: 0}}},
+		{"empty set", set(), map[int]map[uint16]float64{}},
+	}
+	for _, c := range cases {
+		if got := TransitionStatsBySteps(c.set); fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestLoadCSVErrors(t *testing.T) {
+	// 4x4 boards : test files have id,steps + 16 cells, training files 32
+	header := func(cells int) string { return "id,steps" + strings.Repeat(",c", cells) + "\n" }
+	row := func(id string, cells int) string { return id + ",1" + strings.Repeat(",0", cells) + "\n" }
+	cases := []struct {
+		name        string
+		contents    string
+		is_training bool
+		ids         []int
+		err         string // "" : no error
+		loaded      int
+	}{
+		{"good test file", header(16) + row("1", 16) + row("2", 16), false, []int{1, 2}, "", 2},
+		{"good training file", header(32) + row("1", 32), true, []int{1}, "", 1},
+		{"empty file", "", false, []int{1}, "reading header", 0},
+		{"first column not id", "foo,steps" + strings.Repeat(",c", 16) + "\n", false, []int{1}, "bad header", 0},
+		{"header too short", header(10), false, []int{1}, "bad header", 0},
+		{"training header on test columns", header(16), true, []int{1}, "bad header", 0},
+		{"short test record", header(16) + row("1", 16) + row("2", 10), false, []int{1, 2}, "problem[2]", 1},
+		{"short training record", header(32) + row("7", 16), true, []int{7}, "problem[7]", 0},
+		{"bad id", header(16) + row("x", 16), false, []int{1}, "bad id", 0},
+		{"bad steps", header(16) + "1,many" + strings.Repeat(",0", 16) + "\n", false, []int{1}, "bad steps for problem[1]", 0},
+		// Rows past the largest wanted id are never read (ids are ascending), nor are unwanted ones checked
+		{"stops after the last id", header(16) + row("1", 16) + row("2", 16) + row("3", 2), false, []int{1}, "", 1},
+		{"unwanted short row", header(16) + row("1", 2) + row("2", 16), false, []int{2}, "", 1},
+	}
+	path := filepath.Join(t.TempDir(), "problems.csv")
+	for _, c := range cases {
+		if err := os.WriteFile(path, []byte(c.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var s LifeProblemSet
+		err := s.load_csv_from_file_sized(path, ',', c.is_training, true, c.ids, 4, 4)
+		switch {
+		case c.err == "" && err != nil:
+			t.Errorf("%s: %v", c.name, err)
+		case c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)):
+			t.Errorf("%s: error %v, want one mentioning %q", c.name, err, c.err)
+		}
+		if len(s.problem) != c.loaded {
+			t.Errorf("%s: loaded %d problems, want %d", c.name, len(s.problem), c.loaded)
+		}
+	}
+
+	var s LifeProblemSet
+	err := s.load_csv_from_file_sized(filepath.Join(t.TempDir(), "absent.csv"), ',', false, true, []int{1}, 4, 4)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file : error %v, want one wrapping fs.ErrNotExist", err)
+	}
+}
+
+func TestNeighborCounts(t *testing.T) {
+	// X - - X
+	// X X - -
+	// - - - X
+	b := board_from_rows(4, 3, "X--X", "XX--", "---X")
+	cases := []struct {
+		name string
+		wrap bool
+		want [][]int
+	}{
+		{"dead boundary", false, [][]int{{2, 3, 2, 0}, {2, 2, 3, 2}, {2, 2, 2, 0}}},
+		// Only 3 rows : every window spans the whole height, so counts are column sums less the cell itself
+		{"wrapped", true, [][]int{{4, 3, 3, 3}, {4, 2, 3, 4}, {5, 3, 3, 3}}},
+	}
+	for _, c := range cases {
+		b.SetWrap(c.wrap)
+		if got := b.NeighborCounts(); fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	// The counts give the next generation under the Conway rule, whatever the boundary
+	for seed := int64(0); seed < 20; seed++ {
+		b := random_board(board_width, board_height, 0.4, seed)
+		b.SetWrap(seed%2 == 1)
+		counts := b.NeighborCounts()
+		next := NewBoard_BoolPacked(b.w, b.h)
+		b.Iterate(next)
+		for y := 0; y < b.h; y++ {
+			for x := 0; x < b.w; x++ {
+				if RuleConway.next(b.isSet(x, y), counts[y][x]) != next.isSet(x, y) {
+					t.Fatalf("seed %d: count %d at (%d,%d) disagrees with Iterate", seed, counts[y][x], x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	// A row of 10 cells settles into the pentadecathlon
+	pentadecathlon := forward(board_from_rows(board_width, board_height, "", "", "", "", "", "", "", "", "", "", "-----XXXXXXXXXX"), 30)
+	glider := board_from_rows(board_width, board_height, "-X", "--X", "XXX")
+	cases := []struct {
+		name      string
+		board     *Board_BoolPacked
+		maxPeriod int
+		period    int
+	}{
+		{"empty", NewBoard_BoolPacked(5, 5), 3, 1},
+		{"block", board_from_rows(board_width, board_height, "", "", "", "---XX", "---XX"), 10, 1},
+		{"blinker", board_from_rows(board_width, board_height, "", "", "", "", "", "", "", "", "", "", "----------XXX"), 10, 2},
+		{"blinker, limit too low", board_from_rows(board_width, board_height, "", "", "-XXX"), 1, 0},
+		{"toad", board_from_rows(6, 6, "", "", "--XXX", "-XXX"), 5, 2},
+		{"pentadecathlon", pentadecathlon, 20, 15},
+		{"pentadecathlon, limit just short", pentadecathlon, 14, 0},
+		{"glider", glider, 30, 0},
+		{"glider on a torus", func() *Board_BoolPacked { g := glider.Clone(); g.SetWrap(true); return g }(), 100, 80},
+	}
+	for _, c := range cases {
+		before := c.board.toCompactString()
+		if got := c.board.Period(c.maxPeriod); got != c.period {
+			t.Errorf("%s: Period(%d) = %d, want %d", c.name, c.maxPeriod, got, c.period)
+		}
+		if c.board.toCompactString() != before {
+			t.Errorf("%s: Period modified the board", c.name)
+		}
+	}
+}
+
+func TestCrop(t *testing.T) {
+	b := board_from_rows(10, 8, "X", "", "", "---XX", "---X")
+	b.Set(9, 7, true)
+	cases := []struct {
+		name                   string
+		minX, minY, maxX, maxY int
+		w, h, population       int
+	}{
+		{"whole board", 0, 0, 9, 7, 10, 8, 5},
+		{"clamped", -5, -5, 50, 50, 10, 8, 5},
+		{"interior", 3, 3, 4, 4, 2, 2, 3},
+		{"single cell", 9, 7, 9, 7, 1, 1, 1},
+		{"empty region", 5, 0, 8, 2, 4, 3, 0},
+		{"inverted", 5, 5, 2, 2, 0, 0, 0},
+		{"off the board", 20, 20, 30, 30, 0, 0, 0},
+	}
+	for _, c := range cases {
+		cropped := b.Crop(c.minX, c.minY, c.maxX, c.maxY)
+		if cropped.w != c.w || cropped.h != c.h || cropped.Population() != c.population {
+			t.Errorf("%s: got %dx%d with %d live, want %dx%d with %d", c.name,
+				cropped.w, cropped.h, cropped.Population(), c.w, c.h, c.population)
+		}
+	}
+
+	// Cropping to the bounding box keeps every live cell, in a board just big enough
+	for seed := int64(0); seed < 20; seed++ {
+		b := NewBoard_BoolPacked(board_width, board_height)
+		patch := random_board(10, 7, 0.3, seed)
+		for y := 0; y < patch.h; y++ {
+			for x := 0; x < patch.w; x++ {
+				b.Set(3+x, 5+y, patch.isSet(x, y))
+			}
+		}
+		if b.Population() == 0 {
+			continue
+		}
+		minX, minY, maxX, maxY, _ := b.BoundingBox()
+		cropped := b.Crop(minX, minY, maxX, maxY)
+		if cropped.Population() != b.Population() || cropped.w != maxX-minX+1 || cropped.h != maxY-minY+1 {
+			t.Errorf("seed %d: cropping to the bounding box lost cells or size", seed)
+		}
+		for y := 0; y < cropped.h; y++ {
+			for x := 0; x < cropped.w; x++ {
+				if cropped.isSet(x, y) != b.isSet(minX+x, minY+y) {
+					t.Fatalf("seed %d: cropped cell (%d,%d) differs from the original", seed, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestPaste(t *testing.T) {
+	block := board_from_rows(2, 2, "XX", "XX")
+	glider := board_from_rows(3, 3, "-X", "--X", "XXX")
+	type paste_op struct {
+		src        *Board_BoolPacked
+		offX, offY int
+		mode       PasteMode
+	}
+	cases := []struct {
+		name   string
+		dst    *Board_BoolPacked
+		pastes []paste_op
+		want   *Board_BoolPacked
+	}{
+		{"overlapping blocks, or", NewBoard_BoolPacked(6, 6),
+			[]paste_op{{block, 3, 3, PasteOr}, {block, 4, 4, PasteOr}},
+			board_from_rows(6, 6, "", "", "", "---XX", "---XXX", "----XX")},
+		{"overlapping blocks, xor", NewBoard_BoolPacked(6, 6),
+			[]paste_op{{block, 3, 3, PasteXor}, {block, 4, 4, PasteXor}},
+			board_from_rows(6, 6, "", "", "", "---XX", "---X-X", "----XX")},
+		{"copy overwrites with dead cells too", board_from_rows(4, 4, "XXXX", "XXXX", "XXXX", "XXXX"),
+			[]paste_op{{glider, 1, 1, PasteCopy}},
+			board_from_rows(4, 4, "XXXX", "X-X-", "X--X", "XXXX")},
+		{"clipped at every edge", NewBoard_BoolPacked(5, 5),
+			[]paste_op{{block, -1, -1, PasteCopy}, {block, 4, 4, PasteCopy}},
+			board_from_rows(5, 5, "X", "", "", "", "----X")},
+		{"entirely outside", NewBoard_BoolPacked(5, 5),
+			[]paste_op{{glider, 10, 10, PasteOr}, {glider, -3, 0, PasteOr}},
+			NewBoard_BoolPacked(5, 5)},
+	}
+	for _, c := range cases {
+		for _, p := range c.pastes {
+			c.dst.Paste(p.src, p.offX, p.offY, p.mode)
+		}
+		if c.dst.CompareTo(c.want, nil) != 0 {
+			t.Errorf("%s: got\n%s", c.name, c.dst)
+		}
+	}
+
+	// XOR-pasting a scene onto itself empties it
+	scene := NewBoard_BoolPacked(board_width, board_height)
+	scene.Paste(glider, 2, 2, PasteOr)
+	scene.Paste(block, 10, 12, PasteOr)
+	scene.Paste(random_board(6, 6, 0.5, 1), 13, 3, PasteOr)
+	scene.Paste(scene.Clone(), 0, 0, PasteXor)
+	if scene.Population() != 0 {
+		t.Errorf("xor-pasting a board onto itself left %d cells", scene.Population())
+	}
+}
+
+// DrawStats leaves an empty BoardStats as background, instead of dividing by its zero count
+func TestDrawStatsEmpty(t *testing.T) {
+	image := NewImageSet(1, 1)
+	background := image.im.At(5, 5)
+	image.DrawStats(0, 0, NewBoardStats(board_width, board_height))
+	if got := image.im.At(5, 5); got != background {
+		t.Errorf("empty stats drew %v over the background %v", got, background)
+	}
+}
+
+func TestBoardStatsThreshold(t *testing.T) {
+	common := random_board(board_width, board_height, 0.3, 5)
+	unanimous := NewBoardStats(board_width, board_height)
+	for i := 0; i < 5; i++ {
+		common.AddToStats(unanimous)
+	}
+	for _, th := range []float32{0.01, 0.5, 1} {
+		if got := unanimous.Threshold(th); got.HammingDistance(common) != 0 {
+			t.Errorf("unanimous ensemble at Threshold(%g) differs from the common board in %d cells", th, got.HammingDistance(common))
+		}
+	}
+
+	// Cell (0,0) is alive in 3 of 4 boards, (1,0) in 1 of 4 and (2,0) in none
+	split := NewBoardStats(4, 1)
+	for _, row := range []string{"XX", "X", "X", ""} {
+		board_from_rows(4, 1, row).AddToStats(split)
+	}
+	cases := []struct {
+		x          int
+		confidence float32
+	}{
+		{0, 0.75},
+		{1, 0.25},
+		{2, 0},
+	}
+	for _, c := range cases {
+		if got := split.Confidence(c.x, 0); got != c.confidence {
+			t.Errorf("Confidence(%d,0) = %g, want %g", c.x, got, c.confidence)
+		}
+	}
+	thresholds := []struct {
+		t    float32
+		want string
+	}{
+		{0.25, "XX"}, // reaching the threshold is enough
+		{0.5, "X"},
+		{0.75, "X"},
+		{0.76, ""},
+	}
+	for _, c := range thresholds {
+		if got := split.Threshold(c.t); got.HammingDistance(board_from_rows(4, 1, c.want)) != 0 {
+			t.Errorf("Threshold(%g) = %s, want %q", c.t, got.toCompactString(), c.want)
+		}
+	}
+
+	// With nothing added, every cell has zero confidence
+	empty := NewBoardStats(board_width, board_height)
+	if empty.Confidence(3, 3) != 0 || empty.Threshold(0.5).Population() != 0 {
+		t.Error("an empty BoardStats should have no confident cells")
+	}
+}
+
+func TestDrawOverlay(t *testing.T) {
+	blinker := board_from_rows(5, 5, "", "", "-XXX")
+	cases := []struct {
+		name       string
+		start, end *Board_BoolPacked
+		want       map[color.RGBA]int // Count of each colour in the tile
+	}{
+		{"identical", blinker, blinker.Clone(),
+			map[color.RGBA]int{overlay_both_color: 3, overlay_dead_color: 22}},
+		{"blinker", blinker, forward(blinker, 1),
+			map[color.RGBA]int{overlay_both_color: 1, overlay_born_color: 2, overlay_died_color: 2, overlay_dead_color: 20}},
+		{"all born", NewBoard_BoolPacked(5, 5), blinker,
+			map[color.RGBA]int{overlay_born_color: 3, overlay_dead_color: 22}},
+		{"all died", blinker, NewBoard_BoolPacked(5, 5),
+			map[color.RGBA]int{overlay_died_color: 3, overlay_dead_color: 22}},
+	}
+	for _, c := range cases {
+		image := NewImageSetSized(2, 2, 5, 5)
+		image.DrawOverlay(1, 1, c.start, c.end)
+		got := map[color.RGBA]int{}
+		for y := 0; y < 5; y++ {
+			for x := 0; x < 5; x++ {
+				got[image.im.RGBAAt(1*(5+2)+2+x, 1*(5+2)+2+y)]++
+			}
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("%s: tile has colours %v, want %v", c.name, got, c.want)
+		}
+		for col, n := range c.want {
+			if got[col] != n {
+				t.Errorf("%s: %d cells of %v, want %d", c.name, got[col], col, n)
+			}
+		}
+		// The other tiles are untouched
+		if px := image.im.RGBAAt(2, 2); px != (color.RGBA{98, 166, 255, 255}) {
+			t.Errorf("%s: tile (0,0) was drawn over : %v", c.name, px)
+		}
+	}
+}
+
+// Verify takes its scratch boards from StandardBoardPool : "Unpooled" is the same work allocating them each time
+func BenchmarkVerify(b *testing.B) {
+	problem := problem_from_start(1, random_board(board_width, board_height, 0.3, 1), 5)
+	candidate := random_board(board_width, board_height, 0.3, 2)
+	b.Run("Pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			problem.Verify(candidate)
+		}
+	})
+	b.Run("Unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			current, next := NewBoard_BoolPacked(candidate.w, candidate.h), NewBoard_BoolPacked(candidate.w, candidate.h)
+			current.CopyFrom(candidate)
+			for s := 0; s < problem.steps; s++ {
+				current.Iterate(next)
+				current, next = next, current
+			}
+			current.HammingDistance(problem.end)
+		}
+	})
+}
+
+func TestUniformRandomR(t *testing.T) {
+	fill := func(pct float32, seed int64) *Board_BoolPacked {
+		b := NewBoard_BoolPacked(board_width, board_height)
+		b.UniformRandomR(pct, rand.New(rand.NewSource(seed)))
+		return b
+	}
+	cases := []struct {
+		pct                float32
+		min_live, max_live int // For 400 cells
+		seeds_differ       bool
+	}{
+		{0, 0, 0, false},
+		{0.1, 20, 60, true},
+		{0.5, 160, 240, true},
+		{1, 400, 400, false},
+	}
+	for _, c := range cases {
+		for seed := int64(1); seed <= 5; seed++ {
+			a, b := fill(c.pct, seed), fill(c.pct, seed)
+			if a.HammingDistance(b) != 0 {
+				t.Errorf("pct %g seed %d: identically seeded boards differ", c.pct, seed)
+			}
+			if pop := a.Population(); pop < c.min_live || pop > c.max_live {
+				t.Errorf("pct %g seed %d: %d live, want %d..%d", c.pct, seed, pop, c.min_live, c.max_live)
+			}
+			if differ := a.HammingDistance(fill(c.pct, seed+10)) != 0; differ != c.seeds_differ {
+				t.Errorf("pct %g seed %d: differently seeded boards differ=%v, want %v", c.pct, seed, differ, c.seeds_differ)
+			}
+		}
+	}
+}
+
+func TestSymmetries(t *testing.T) {
+	all := SymMirrorH | SymMirrorV | SymRotate180 | SymDiagonal | SymAntiDiagonal
+	cases := []struct {
+		name string
+		b    *Board_BoolPacked
+		want SymFlags
+	}{
+		// Exactly one symmetry each
+		{"mirror-H", board_from_rows(5, 5, "X---X"), SymMirrorH},
+		{"mirror-V", board_from_rows(5, 5, "X", "", "", "", "X"), SymMirrorV},
+		{"rotate-180", board_from_rows(5, 5, "-X", "", "", "", "---X"), SymRotate180},
+		{"diagonal", board_from_rows(5, 5, "-X", "X"), SymDiagonal},
+		{"anti-diagonal", board_from_rows(5, 5, "-X", "", "", "----X"), SymAntiDiagonal},
+
+		{"empty", NewBoard_BoolPacked(5, 5), all},
+		{"glider", board_from_rows(5, 5, "-X", "--X", "XXX"), 0},
+		{"blinker", board_from_rows(5, 5, "", "", "-XXX"), SymMirrorH | SymMirrorV | SymRotate180},
+		{"centre cell", board_from_rows(5, 5, "", "", "--X"), all},
+		{"not square", board_from_rows(6, 4, "X----X", "", "", "X----X"), SymMirrorH | SymMirrorV | SymRotate180},
+		{"not square, empty", NewBoard_BoolPacked(6, 4), SymMirrorH | SymMirrorV | SymRotate180},
+	}
+	for _, c := range cases {
+		if got := c.b.Symmetries(); got != c.want {
+			t.Errorf("%s: Symmetries = %05b, want %05b", c.name, got, c.want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	glider := board_from_rows(board_width, board_height, "-X", "--X", "XXX")
+	blinker := board_from_rows(8, 8, "", "", "-XXX")
+	cases := []struct {
+		name      string
+		problem   LifeProblem
+		candidate *Board_BoolPacked
+		matches   bool
+		wrong     int
+	}{
+		{"glider, 1 step", problem_from_start(1, glider, 1), glider, true, 0},
+		{"glider, 5 steps", problem_from_start(2, glider, 5), glider, true, 0},
+		{"random start, 3 steps", problem_from_start(3, random_board(board_width, board_height, 0.3, 3), 3), random_board(board_width, board_height, 0.3, 3), true, 0},
+		{"another predecessor of an empty end", problem_from_start(4, board_from_rows(board_width, board_height, "X"), 1), board_from_rows(board_width, board_height, "", "---X", "", "-----X"), true, 0},
+		{"blinker a phase out", problem_from_start(5, blinker, 1), forward(blinker, 1), false, 4},
+		{"blank candidate for a glider", problem_from_start(6, glider, 4), NewBoard_BoolPacked(board_width, board_height), false, 5},
+		{"blinker, 2 steps (not the standard size)", problem_from_start(7, blinker, 2), blinker, true, 0},
+	}
+	for _, c := range cases {
+		before := c.candidate.Clone()
+		matches, wrong := c.problem.Verify(c.candidate)
+		if matches != c.matches || wrong != c.wrong {
+			t.Errorf("%s: Verify = (%v, %d), want (%v, %d)", c.name, matches, wrong, c.matches, c.wrong)
+		}
+		if c.candidate.HammingDistance(before) != 0 {
+			t.Errorf("%s: Verify changed the candidate", c.name)
+		}
+	}
+}
+
+func TestBoardStatsWriteCSV(t *testing.T) {
+	// 4x3 stats from three boards : (0,0) in all, (1,0) in two, (3,2) in one
+	small := NewBoardStats(4, 3)
+	for _, rows := range [][]string{{"XX"}, {"XX", "", "---X"}, {"X"}} {
+		board_from_rows(4, 3, rows...).AddToStats(small)
+	}
+	small.MisMatchBy(7)
+
+	cases := []struct {
+		name   string
+		bs     *BoardStats
+		header string
+		w, h   int
+		cells  map[[2]int]string // Known values by (x,y), anything else is "0"
+	}{
+		{"small", small, "# count=3 mismatch_amount=7", 4, 3,
+			map[[2]int]string{{0, 0}: "1", {1, 0}: "0.6666667", {3, 2}: "0.33333334"}},
+		{"empty", NewBoardStats(board_width, board_height), "# count=0 mismatch_amount=0", board_width, board_height, nil},
+	}
+	for _, c := range cases {
+		var buf strings.Builder
+		if err := c.bs.WriteCSV(&buf); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if lines[0] != c.header {
+			t.Errorf("%s: header %q, want %q", c.name, lines[0], c.header)
+		}
+		if len(lines)-1 != c.h {
+			t.Fatalf("%s: %d rows, want %d", c.name, len(lines)-1, c.h)
+		}
+		for y, line := range lines[1:] {
+			fields := strings.Split(line, ",")
+			if len(fields) != c.w {
+				t.Fatalf("%s: row %d has %d columns, want %d", c.name, y, len(fields), c.w)
+			}
+			for x, field := range fields {
+				want, ok := c.cells[[2]int{x, y}]
+				if !ok {
+					want = "0"
+				}
+				if field != want {
+					t.Errorf("%s: (%d,%d) = %q, want %q", c.name, x, y, field, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"sync"
+)
+
+// Identifies a (board, steps) pair, e.g. a forward iteration or a problem
+type board_steps_key struct {
+	board uint64 // Hash() of the board
+	steps int
+}
+
+// ForwardCache remembers the result of forward-iterating boards, for solvers that keep revisiting the same candidates.
+// Safe to share between workers
+type ForwardCache struct {
+	mutex   sync.Mutex
+	entries map[board_steps_key]*Board_BoolPacked
+
+	hits, misses int
+}
+
+func NewForwardCache() *ForwardCache {
+	return &ForwardCache{entries: make(map[board_steps_key]*Board_BoolPacked)}
+}
+
+// Forward returns start iterated by steps (a fresh board, which the caller is free to modify)
+func (c *ForwardCache) Forward(start *Board_BoolPacked, steps int) *Board_BoolPacked {
+	key := board_steps_key{board: start.Hash(), steps: steps}
+
+	c.mutex.Lock()
+	end, ok := c.entries[key]
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	c.mutex.Unlock()
+
+	if !ok {
+		l := NewBoardIterator(start.w, start.h)
+		l.current.CopyFrom(start)
+		l.Iterate(steps)
+		end = NewBoard_BoolPacked(start.w, start.h)
+		end.CopyFrom(l.current)
+
+		c.mutex.Lock()
+		c.entries[key] = end
+		c.mutex.Unlock()
+	}
+
+	result := NewBoard_BoolPacked(end.w, end.h)
+	result.CopyFrom(end)
+	return result
+}
+
+// Stats reports how many Forward calls were answered from the cache, and how many had to iterate
+func (c *ForwardCache) Stats() (hits, misses int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.hits, c.misses
+}
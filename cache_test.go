@@ -0,0 +1,38 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"testing"
+)
+
+func TestForwardCacheStats(t *testing.T) {
+	boards := []*Board_BoolPacked{random_board(board_width, board_height, 0.3, 1), random_board(board_width, board_height, 0.3, 2)}
+	type lookup struct{ board, steps int }
+	cases := []struct {
+		name         string
+		lookups      []lookup
+		hits, misses int
+	}{
+		{"single", []lookup{{0, 3}}, 0, 1},
+		{"repeated key", []lookup{{0, 3}, {0, 3}, {0, 3}}, 2, 1},
+		{"distinct steps", []lookup{{0, 1}, {0, 2}, {0, 3}}, 0, 3},
+		{"distinct boards", []lookup{{0, 3}, {1, 3}}, 0, 2},
+		{"mixed", []lookup{{0, 3}, {1, 3}, {0, 3}, {0, 2}, {1, 3}}, 2, 3},
+	}
+	for _, c := range cases {
+		cache := NewForwardCache()
+		for _, k := range c.lookups {
+			end := cache.Forward(boards[k.board], k.steps)
+			if end.CompareTo(forward(boards[k.board], k.steps), nil) != 0 {
+				t.Errorf("%s: Forward(board %d, %d) is not the board iterated", c.name, k.board, k.steps)
+			}
+			// Results are the caller's to modify, and must not leak back into the cache
+			end.Set(0, 0, !end.isSet(0, 0))
+		}
+		if hits, misses := cache.Stats(); hits != c.hits || misses != c.misses {
+			t.Errorf("%s: Stats() = %d hits, %d misses, want %d, %d", c.name, hits, misses, c.hits, c.misses)
+		}
+	}
+}
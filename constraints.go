@@ -0,0 +1,600 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"sort"
+)
+
+// One-step constraint propagation :
+//   Each cell of the end board constrains the 3x3 neighbourhood of the board one step before it.
+//   By enumerating the 512 possible neighbourhoods for every end cell, and only keeping the
+//   values that appear in at least one consistent neighbourhood, the previous board's cells
+//   get narrowed down to {dead}, {alive} or {dead,alive} (free).
+//   A single-cell look-ahead on top of that catches most of what plain propagation misses.
+//   Cells outside the board are dead (same convention as isSet_safe).
+
+const (
+	domain_dead  uint8 = 1
+	domain_alive uint8 = 2
+	domain_free  uint8 = domain_dead | domain_alive
+)
+
+// Neighbourhood codes use the same bit layout as the lookup in Iterate() :
+//   top row in bits 6..8, middle row in bits 3..5, bottom row in bits 0..2 (lowest bit = left-most column)
+func window_bit(dx, dy int) uint {
+	return uint((1-dy)*3 + (dx+1))
+}
+
+const window_center = 1<<4
+
+// Conway's B3/S23 rule applied to a 3x3 neighbourhood code
+func conway_next(code int) bool {
+	alive := int(count_bits_array[code & ^window_center])
+	return alive == 3 || alive == 2 && (code & window_center) != 0
+}
+
+type CellDomains struct {
+	d    [][]uint8
+	w, h int
+}
+
+func (cd *CellDomains) get(x, y int) uint8 {
+	if x<0 || x>=cd.w || y<0 || y>=cd.h {
+		return domain_dead
+	}
+	return cd.d[y][x]
+}
+
+// PropagateConstraints narrows down the cells of any board that iterates to 'end' in one step.
+// Returns false if some cell has no consistent value left (i.e. 'end' has no predecessor at all)
+func PropagateConstraints(end *Board_BoolPacked) (*CellDomains, bool) {
+	d := make([][]uint8, end.h)
+	for y := range d {
+		d[y] = make([]uint8, end.w)
+		for x := range d[y] {
+			d[y][x] = domain_free
+		}
+	}
+	cd := &CellDomains{d: d, w: end.w, h: end.h}
+
+	all := [][2]int{}
+	for y := 0; y < end.h; y++ {
+		for x := 0; x < end.w; x++ {
+			all = append(all, [2]int{x, y})
+		}
+	}
+	if !cd.propagate(end, all) {
+		return cd, false
+	}
+
+	// Single-cell look-ahead : if fixing a free cell to one value leads to a contradiction, 
+	// then it must have the other value.  Repeat until nothing more falls out
+	for changed := true; changed; {
+		changed = false
+		for y := 0; y < cd.h; y++ {
+			for x := 0; x < cd.w; x++ {
+				if cd.d[y][x] != domain_free {
+					continue
+				}
+				for _, v := range []uint8{domain_dead, domain_alive} {
+					trial := cd.clone()
+					trial.d[y][x] = v
+					if trial.propagate(end, cd.window_around(x, y)) {
+						continue
+					}
+					// v is impossible here
+					cd.d[y][x] = domain_free &^ v
+					if !cd.propagate(end, cd.window_around(x, y)) {
+						return cd, false
+					}
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return cd, true
+}
+
+func (cd *CellDomains) clone() *CellDomains {
+	d := make([][]uint8, cd.h)
+	for y := range d {
+		d[y] = make([]uint8, cd.w)
+		copy(d[y], cd.d[y])
+	}
+	return &CellDomains{d: d, w: cd.w, h: cd.h}
+}
+
+// The end cells that 'see' the previous-board cell at (x,y)
+func (cd *CellDomains) window_around(x, y int) [][2]int {
+	cells := [][2]int{}
+	for ny := y-1; ny <= y+1; ny++ {
+		for nx := x-1; nx <= x+1; nx++ {
+			if nx<0 || nx>=cd.w || ny<0 || ny>=cd.h {
+				continue
+			}
+			cells = append(cells, [2]int{nx, ny})
+		}
+	}
+	return cells
+}
+
+// Revisits the given end cells (and any end cells affected by narrowing) until nothing changes
+func (cd *CellDomains) propagate(end *Board_BoolPacked, initial [][2]int) bool {
+	queued := make([][]bool, cd.h)
+	for y := range queued {
+		queued[y] = make([]bool, cd.w)
+	}
+	queue := [][2]int{}
+	for _, c := range initial {
+		if !queued[c[1]][c[0]] {
+			queued[c[1]][c[0]] = true
+			queue = append(queue, c)
+		}
+	}
+
+	for len(queue) > 0 {
+		x, y := queue[0][0], queue[0][1]
+		queue = queue[1:]
+		queued[y][x] = false
+
+		target := end.isSet(x, y)
+
+		// supported[i] collects the values seen for window cell i amongst the consistent neighbourhoods
+		var supported [9]uint8
+		for code := 0; code < 512; code++ {
+			allowed := true
+			for dy := -1; dy <= 1 && allowed; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					v := domain_dead
+					if code&(1<<window_bit(dx, dy)) != 0 {
+						v = domain_alive
+					}
+					if cd.get(x+dx, y+dy)&v == 0 {
+						allowed = false
+						break
+					}
+				}
+			}
+			if !allowed || conway_next(code) != target {
+				continue
+			}
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					i := (dy+1)*3 + (dx+1)
+					if code&(1<<window_bit(dx, dy)) != 0 {
+						supported[i] |= domain_alive
+					} else {
+						supported[i] |= domain_dead
+					}
+				}
+			}
+		}
+
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				px, py := x+dx, y+dy
+				if px<0 || px>=cd.w || py<0 || py>=cd.h {
+					continue
+				}
+				narrowed := cd.d[py][px] & supported[(dy+1)*3+(dx+1)]
+				if narrowed == cd.d[py][px] {
+					continue
+				}
+				cd.d[py][px] = narrowed
+				if narrowed == 0 {
+					return false
+				}
+				// Every end cell that sees this cell needs another look
+				for _, c := range cd.window_around(px, py) {
+					if !queued[c[1]][c[0]] {
+						queued[c[1]][c[0]] = true
+						queue = append(queue, c)
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// CountForcedCells reports how constrained the one-step predecessor of 'end' is :
+//   forcedAlive + forcedDead cells are fully determined by constraint propagation, the rest are free.
+// If propagation proves that 'end' has no predecessor at all, some cell has no value left, so the counts
+// would not add up to w*h : An error is returned instead (with zero counts).  Propagation doesn't catch
+// every such board though, so a nil error doesn't promise that a predecessor exists
+func CountForcedCells(end *Board_BoolPacked) (forcedAlive, forcedDead, free int, err error) {
+	cd, ok := PropagateConstraints(end)
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("the %dx%d end has no one-step predecessor", end.w, end.h)
+	}
+	for y := 0; y < cd.h; y++ {
+		for x := 0; x < cd.w; x++ {
+			switch cd.d[y][x] {
+			case domain_alive:
+				forcedAlive++
+			case domain_dead:
+				forcedDead++
+			case domain_free:
+				free++
+			}
+		}
+	}
+	return forcedAlive, forcedDead, free, nil
+}
+
+// ConstraintTightness is the fraction of the predecessor's cells that the end board alone forces : 
+// 1.0 means the predecessor is completely determined, lower means more freedom for the solvers.
+// As with CountForcedCells, an end shown to have no predecessor is an error
+func ConstraintTightness(end *Board_BoolPacked) (float64, error) {
+	forcedAlive, forcedDead, _, err := CountForcedCells(end)
+	if err != nil {
+		return 0, err
+	}
+	return float64(forcedAlive+forcedDead) / float64(end.w*end.h), nil
+}
+
+// RegionDifficulties scores each tileW x tileH tile of 'end' (the last row/column of tiles may be smaller) :
+//   activity = (live density + churn, the fraction of cells that change in a forward step) / 2
+//   difficulty = activity * (2 - constraint tightness) / 2
+// so empty, static tiles score 0, and looser constraints make the same activity harder.
+// Churn and tightness are computed over the whole board, so tiles see their neighbours' influence
+func RegionDifficulties(end *Board_BoolPacked, tileW, tileH int) map[image.Rectangle]float64 {
+	next := NewBoard_BoolPacked(end.w, end.h)
+	end.Iterate(next)
+	cd, _ := PropagateConstraints(end)
+
+	difficulties := make(map[image.Rectangle]float64)
+	for y0 := 0; y0 < end.h; y0 += tileH {
+		for x0 := 0; x0 < end.w; x0 += tileW {
+			tile := image.Rect(x0, y0, x0+tileW, y0+tileH).Intersect(image.Rect(0, 0, end.w, end.h))
+			live, churn, forced := 0, 0, 0
+			for y := tile.Min.Y; y < tile.Max.Y; y++ {
+				for x := tile.Min.X; x < tile.Max.X; x++ {
+					if end.isSet(x, y) {
+						live++
+					}
+					if end.isSet(x, y) != next.isSet(x, y) {
+						churn++
+					}
+					if cd.d[y][x] != domain_free {
+						forced++
+					}
+				}
+			}
+			cells := float64(tile.Dx() * tile.Dy())
+			activity := (float64(live) + float64(churn)) / cells / 2
+			difficulties[tile] = activity * (2 - float64(forced)/cells) / 2
+		}
+	}
+	return difficulties
+}
+
+// Order in which SolveBruteForce assigns the cells left free by constraint propagation
+type EnumerationOrder int
+
+const (
+	OrderRowMajor EnumerationOrder = iota
+	OrderSpiral                    // From the border inwards, where the dead boundary constrains things most
+	OrderMostConstrainedFirst      // Cells seeing the most live end cells first (these are the tight constraints)
+)
+
+func (cd *CellDomains) free_cells(end *Board_BoolPacked, order EnumerationOrder) [][2]int {
+	cells := [][2]int{}
+	add := func(x, y int) {
+		if cd.d[y][x] == domain_free {
+			cells = append(cells, [2]int{x, y})
+		}
+	}
+
+	switch order {
+	case OrderSpiral:
+		for ring := 0; 2*ring < cd.w && 2*ring < cd.h; ring++ {
+			x0, y0, x1, y1 := ring, ring, cd.w-1-ring, cd.h-1-ring
+			for x := x0; x <= x1; x++ {
+				add(x, y0)
+			}
+			for y := y0+1; y <= y1; y++ {
+				add(x1, y)
+			}
+			if y1 > y0 {
+				for x := x1-1; x >= x0; x-- {
+					add(x, y1)
+				}
+			}
+			if x1 > x0 {
+				for y := y1-1; y > y0; y-- {
+					add(x0, y)
+				}
+			}
+		}
+	default:
+		for y := 0; y < cd.h; y++ {
+			for x := 0; x < cd.w; x++ {
+				add(x, y)
+			}
+		}
+	}
+
+	if order == OrderMostConstrainedFirst {
+		live_around := func(c [2]int) int {
+			n := 0
+			for _, e := range cd.window_around(c[0], c[1]) {
+				if end.isSet(e[0], e[1]) {
+					n++
+				}
+			}
+			return n
+		}
+		sort.SliceStable(cells, func(i, j int) bool {
+			return live_around(cells[i]) > live_around(cells[j])
+		})
+	}
+	return cells
+}
+
+// SolveBruteForce searches for an exact one-step predecessor of 'end' :
+//   Constraint propagation fixes what it can, then the free cells are assigned (in the given order)
+//   with backtracking as soon as some end cell can no longer be satisfied.
+// Gives up (returning nil, false) after max_nodes assignments, or if there is no predecessor
+func SolveBruteForce(end *Board_BoolPacked, max_nodes int, order EnumerationOrder) (*Board_BoolPacked, bool) {
+	cd, ok := PropagateConstraints(end)
+	if !ok {
+		return nil, false
+	}
+
+	// assigned : -1 for 'not yet', 0 or 1 otherwise
+	assigned := make([][]int8, cd.h)
+	for y := range assigned {
+		assigned[y] = make([]int8, cd.w)
+		for x := range assigned[y] {
+			switch cd.d[y][x] {
+			case domain_alive:
+				assigned[y][x] = 1
+			case domain_dead:
+				assigned[y][x] = 0
+			default:
+				assigned[y][x] = -1
+			}
+		}
+	}
+	cell := func(x, y int) int8 {
+		if x<0 || x>=cd.w || y<0 || y>=cd.h {
+			return 0
+		}
+		return assigned[y][x]
+	}
+
+	// Can the end cell at (x,y) still come out right, given the partial assignment?
+	satisfiable := func(x, y int) bool {
+		alive, unknown := 0, 0
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if dx == 0 && dy == 0 {
+					continue
+				}
+				switch cell(x+dx, y+dy) {
+				case 1:
+					alive++
+				case -1:
+					unknown++
+				}
+			}
+		}
+		target := end.isSet(x, y)
+		center := cell(x, y)
+		for n := alive; n <= alive+unknown; n++ {
+			for c := int8(0); c <= 1; c++ {
+				if center >= 0 && center != c {
+					continue
+				}
+				if (n == 3 || n == 2 && c == 1) == target {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	free := cd.free_cells(end, order)
+	nodes := 0
+
+	// Backjumping : free cells interact only if they share an end cell (i.e. are within 2 of each other),
+	// so a dead-end only needs to undo the most recent cell it actually conflicts with
+	index := make([][]int, cd.h)
+	for y := range index {
+		index[y] = make([]int, cd.w)
+		for x := range index[y] {
+			index[y][x] = -1
+		}
+	}
+	for i, c := range free {
+		index[c[1]][c[0]] = i
+	}
+	earlier_neighbours := func(i int) []int {
+		x, y := free[i][0], free[i][1]
+		n := []int{}
+		for ny := y-2; ny <= y+2; ny++ {
+			for nx := x-2; nx <= x+2; nx++ {
+				if nx<0 || nx>=cd.w || ny<0 || ny>=cd.h {
+					continue
+				}
+				if j := index[ny][nx]; j >= 0 && j < i {
+					n = append(n, j)
+				}
+			}
+		}
+		return n
+	}
+
+	// Returns success, or else the set of earlier cells responsible for the failure
+	var search func(i int) (bool, map[int]bool)
+	search = func(i int) (bool, map[int]bool) {
+		if i == len(free) {
+			return true, nil
+		}
+		x, y := free[i][0], free[i][1]
+		conflicts := make(map[int]bool)
+		for _, v := range []int8{0, 1} {
+			nodes++
+			if nodes > max_nodes {
+				return false, nil
+			}
+			assigned[y][x] = v
+			consistent := true
+			for _, e := range cd.window_around(x, y) {
+				if !satisfiable(e[0], e[1]) {
+					consistent = false
+					break
+				}
+			}
+			if !consistent {
+				for _, j := range earlier_neighbours(i) {
+					conflicts[j] = true
+				}
+				continue
+			}
+			found, deeper := search(i+1)
+			if found {
+				return true, nil
+			}
+			if deeper == nil { // Ran out of nodes
+				assigned[y][x] = -1
+				return false, nil
+			}
+			if !deeper[i] { // This cell isn't to blame : jump straight past it
+				assigned[y][x] = -1
+				return false, deeper
+			}
+			for j := range deeper {
+				if j != i {
+					conflicts[j] = true
+				}
+			}
+		}
+		assigned[y][x] = -1
+		return false, conflicts
+	}
+
+	if found, _ := search(0); !found {
+		return nil, false
+	}
+
+	start := NewBoard_BoolPacked(end.w, end.h)
+	for y := 0; y < cd.h; y++ {
+		for x := 0; x < cd.w; x++ {
+			start.Set(x, y, assigned[y][x] == 1)
+		}
+	}
+	return start, true
+}
+
+// EstimatePredecessorCount is a heuristic for how many one-step predecessors 'end' has :
+//   2^(cells) * product over end cells of Prob(a random neighbourhood gives that cell's value).
+// This treats the (overlapping) neighbourhoods as independent, so it's only good for comparing difficulty
+func EstimatePredecessorCount(end *Board_BoolPacked) float64 {
+	log_count := float64(end.w*end.h) * math.Log(2)
+	for y := 0; y < end.h; y++ {
+		for x := 0; x < end.w; x++ {
+			target := end.isSet(x, y)
+			consistent, total := 0, 0
+			for code := 0; code < 512; code++ {
+				// Neighbourhoods that put life outside the board aren't possible
+				outside := false
+				for dy := -1; dy <= 1 && !outside; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						px, py := x+dx, y+dy
+						if (px<0 || px>=end.w || py<0 || py>=end.h) && code&(1<<window_bit(dx, dy)) != 0 {
+							outside = true
+							break
+						}
+					}
+				}
+				if outside {
+					continue
+				}
+				total++
+				if conway_next(code) == target {
+					consistent++
+				}
+			}
+			if consistent == 0 {
+				return 0
+			}
+			log_count += math.Log(float64(consistent) / float64(total))
+		}
+	}
+	return math.Exp(log_count)
+}
+
+// ReverseCNF encodes "which boards iterate to p.end in one step" as CNF, with DIMACS conventions : 
+// variable varOf(x,y) (numbered from 1) is the previous board's cell, a positive literal means alive, 
+// and a negative one dead.  Cells outside the board are dead, so simply don't appear.
+// The encoding is direct : for each end cell, one clause rules out each assignment of its (in-board) 3x3 
+// window that B3/S23 maps to the wrong value - so up to 512 clauses per cell, but no auxiliary variables.
+// Only meaningful for p.steps==1
+func (p *LifeProblem) ReverseCNF() (clauses [][]int, varOf func(x, y int) int) {
+	w, h := p.end.w, p.end.h
+	varOf = func(x, y int) int {
+		return y*w + x + 1
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			target := p.end.isSet(x, y)
+
+			// Which window bits are on the board (the rest are dead)
+			on_board := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if x+dx >= 0 && x+dx < w && y+dy >= 0 && y+dy < h {
+						on_board |= 1 << window_bit(dx, dy)
+					}
+				}
+			}
+
+			for code := 0; code < 512; code++ {
+				if code & ^on_board != 0 || conway_next(code) == target {
+					continue
+				}
+				clause := []int{}
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						bit := 1 << window_bit(dx, dy)
+						if on_board&bit == 0 {
+							continue
+						}
+						if code&bit != 0 {
+							clause = append(clause, -varOf(x+dx, y+dy))
+						} else {
+							clause = append(clause, varOf(x+dx, y+dy))
+						}
+					}
+				}
+				clauses = append(clauses, clause)
+			}
+		}
+	}
+	return clauses, varOf
+}
+
+// WriteDIMACS writes clauses (e.g. from ReverseCNF, which uses variables 1..w*h) in the DIMACS CNF format 
+// that SAT solvers read
+func WriteDIMACS(w io.Writer, clauses [][]int, variables int) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p cnf %d %d\n", variables, len(clauses))
+	for _, clause := range clauses {
+		for _, literal := range clause {
+			fmt.Fprintf(bw, "%d ", literal)
+		}
+		fmt.Fprintln(bw, "0")
+	}
+	return bw.Flush()
+}
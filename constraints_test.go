@@ -0,0 +1,274 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestCountForcedCells(t *testing.T) {
+	cells := board_width * board_height
+	cases := []struct {
+		name        string
+		end         *Board_BoolPacked
+		alive, dead int
+		impossible  bool
+	}{
+		{"empty board is unconstrained", NewBoard_BoolPacked(board_width, board_height), 0, 0, false},
+		{"corner elbow", board_from_rows(board_width, board_height, "XX", "-X"), 3, 4, false},
+		{"corner pentomino", board_from_rows(board_width, board_height, "XXX", "XX"), 1, 0, false},
+		{"corner diagonal has no predecessor", board_from_rows(board_width, board_height, "X", "-X"), 0, 0, true},
+		{"corner arrow has no predecessor", board_from_rows(board_width, board_height, "X-X", "-X"), 0, 0, true},
+	}
+	for _, c := range cases {
+		alive, dead, free, err := CountForcedCells(c.end)
+		if (err != nil) != c.impossible {
+			t.Errorf("%s: err = %v, want an error %v", c.name, err, c.impossible)
+		}
+		want_free := cells - c.alive - c.dead
+		if c.impossible {
+			want_free = 0
+		}
+		if alive != c.alive || dead != c.dead || free != want_free {
+			t.Errorf("%s: got alive=%d dead=%d free=%d, want %d/%d/%d", c.name, alive, dead, free, c.alive, c.dead, want_free)
+		}
+	}
+}
+
+// Every cell reported as forced must take that value in every real predecessor : checked on known predecessors
+func TestCountForcedCellsSound(t *testing.T) {
+	starts := []*Board_BoolPacked{
+		board_from_rows(board_width, board_height, "XX", "X", "", "", "-----XXX"),
+		random_board(board_width, board_height, 0.2, 1),
+		random_board(board_width, board_height, 0.4, 2),
+	}
+	for i, start := range starts {
+		cd, ok := PropagateConstraints(forward(start, 1))
+		if !ok {
+			t.Fatalf("start %d : propagation found no predecessor for its own successor", i)
+		}
+		for y := 0; y < board_height; y++ {
+			for x := 0; x < board_width; x++ {
+				v := domain_dead
+				if start.isSet(x, y) {
+					v = domain_alive
+				}
+				if cd.d[y][x]&v == 0 {
+					t.Fatalf("start %d : cell (%d,%d) forced away from the real predecessor's value", i, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestSolveBruteForceOrders(t *testing.T) {
+	orders := []EnumerationOrder{OrderRowMajor, OrderSpiral, OrderMostConstrainedFirst}
+	cases := []struct {
+		name   string
+		end    *Board_BoolPacked
+		unique *Board_BoolPacked // The only predecessor, if there is just the one
+	}{
+		{"unique 4x4 a", board_from_rows(4, 4, "-XX-", "-X--", "X-X-"), board_from_rows(4, 4, "--XX", "XX--", "--XX", "X---")},
+		{"unique 4x4 b", board_from_rows(4, 4, "", "-X-X", "---X", "XXX-"), board_from_rows(4, 4, "--X-", "X--X", "-X-X", "XXX-")},
+		{"glider on 20x20", forward(board_from_rows(20, 20, "", "", "", "", "", "-----X", "------X", "----XXX"), 1), nil},
+		{"random 6x6", forward(random_board(6, 6, 0.3, 5), 1), nil},
+	}
+	for _, c := range cases {
+		for _, order := range orders {
+			start, ok := SolveBruteForce(c.end, 2000000, order)
+			if !ok {
+				t.Errorf("%s: order %v found no predecessor", c.name, order)
+				continue
+			}
+			if forward(start, 1).CompareTo(c.end, nil) != 0 {
+				t.Errorf("%s: order %v returned a start that doesn't reach the end", c.name, order)
+			}
+			if c.unique != nil && start.CompareTo(c.unique, nil) != 0 {
+				t.Errorf("%s: order %v found a different start than the unique one", c.name, order)
+			}
+		}
+	}
+}
+
+func TestEstimatePredecessorCount(t *testing.T) {
+	dense := forward(random_board(board_width, board_height, 0.4, 2), 1)
+	cases := []struct {
+		name            string
+		looser, tighter *Board_BoolPacked
+	}{
+		{"empty vs dense", NewBoard_BoolPacked(board_width, board_height), dense},
+		{"empty vs blinker", NewBoard_BoolPacked(5, 5), board_from_rows(5, 5, "", "", "-XXX")},
+		{"one live cell vs two", board_from_rows(6, 6, "", "", "--X"), board_from_rows(6, 6, "", "", "--X", "", "----X")},
+	}
+	for _, c := range cases {
+		l, h := EstimatePredecessorCount(c.looser), EstimatePredecessorCount(c.tighter)
+		if !(l > h) {
+			t.Errorf("%s: estimates %g and %g, want the first higher", c.name, l, h)
+		}
+	}
+
+	// A live cell with no room for neighbours has no predecessor at all
+	if got := EstimatePredecessorCount(board_from_rows(1, 1, "X")); got != 0 {
+		t.Errorf("impossible 1x1: estimate %g, want 0", got)
+	}
+}
+
+func TestConstraintTightness(t *testing.T) {
+	cases := []struct {
+		name       string
+		end        *Board_BoolPacked
+		want       float64
+		impossible bool
+	}{
+		{"lone dot is fully determined", board_from_rows(3, 1, "-X-"), 1, false},
+		{"top row of 3x3 is fully determined", board_from_rows(3, 3, "XXX"), 1, false},
+		{"partly forced", board_from_rows(3, 3, "XX-", "X--"), 4.0 / 9, false},
+		{"two full rows", board_from_rows(3, 2, "XXX", "XXX"), 2.0 / 6, false},
+		{"empty board is unconstrained", NewBoard_BoolPacked(6, 6), 0, false},
+		{"full 3x3 has no predecessor", board_from_rows(3, 3, "XXX", "XXX", "XXX"), 0, true},
+	}
+	for _, c := range cases {
+		got, err := ConstraintTightness(c.end)
+		if (err != nil) != c.impossible {
+			t.Errorf("%s: err = %v, want an error %v", c.name, err, c.impossible)
+		}
+		if got != c.want {
+			t.Errorf("%s: ConstraintTightness() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRegionDifficulties(t *testing.T) {
+	// A dense, churning soup in the top-left quarter, a still-life block in the top-right, the rest empty
+	end := NewBoard_BoolPacked(board_width, board_height)
+	soup := random_board(10, 10, 0.5, 1)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			end.Set(x, y, soup.isSet(x, y))
+		}
+	}
+	end.SetCells([][2]int{{14, 4}, {15, 4}, {14, 5}, {15, 5}})
+
+	counts := []struct {
+		tileW, tileH, tiles int
+	}{
+		{10, 10, 4},
+		{7, 7, 9},
+		{20, 20, 1},
+		{3, 20, 7},
+		{1, 1, board_width * board_height},
+	}
+	for _, c := range counts {
+		d := RegionDifficulties(end, c.tileW, c.tileH)
+		if len(d) != c.tiles {
+			t.Errorf("%dx%d tiles : got %d, want %d", c.tileW, c.tileH, len(d), c.tiles)
+		}
+		for tile, score := range d {
+			if score < 0 || score > 1 {
+				t.Errorf("%dx%d tiles : %v scored %v, outside [0,1]", c.tileW, c.tileH, tile, score)
+			}
+		}
+	}
+
+	d := RegionDifficulties(end, 10, 10)
+	dense, still, empty := d[image.Rect(0, 0, 10, 10)], d[image.Rect(10, 0, 20, 10)], d[image.Rect(10, 10, 20, 20)]
+	if empty != 0 {
+		t.Errorf("empty tile scored %v, want 0", empty)
+	}
+	if !(dense > still && still > empty) {
+		t.Errorf("want dense soup (%v) > still life (%v) > empty (%v)", dense, still, empty)
+	}
+}
+
+// Every 3x3 end : the assignments satisfying ReverseCNF are exactly its predecessors (found by brute force)
+func TestReverseCNF(t *testing.T) {
+	board_of := func(code int) *Board_BoolPacked {
+		b := NewBoard_BoolPacked(3, 3)
+		for i := 0; i < 9; i++ {
+			b.Set(i%3, i/3, code&(1<<i) != 0)
+		}
+		return b
+	}
+	next := make([]*Board_BoolPacked, 512)
+	for code := range next {
+		next[code] = forward(board_of(code), 1)
+	}
+
+	with_predecessor, orphans := 0, 0
+	for end_code := 0; end_code < 512; end_code++ {
+		end := board_of(end_code)
+		clauses, varOf := (&LifeProblem{steps: 1, end: end}).ReverseCNF()
+		satisfiable := false
+		for code := 0; code < 512; code++ {
+			satisfies := true
+			for _, clause := range clauses {
+				clause_true := false
+				for _, literal := range clause {
+					v := literal
+					if v < 0 {
+						v = -v
+					}
+					x, y := (v-1)%3, (v-1)/3
+					if varOf(x, y) != v {
+						t.Fatalf("varOf(%d,%d) = %d, want %d", x, y, varOf(x, y), v)
+					}
+					if (code&(1<<(v-1)) != 0) == (literal > 0) {
+						clause_true = true
+						break
+					}
+				}
+				if !clause_true {
+					satisfies = false
+					break
+				}
+			}
+			if is_predecessor := next[code].HammingDistance(end) == 0; satisfies != is_predecessor {
+				t.Fatalf("end %03x, start %03x : satisfies the CNF = %v, but is a predecessor = %v", end_code, code, satisfies, is_predecessor)
+			}
+			satisfiable = satisfiable || satisfies
+		}
+		if satisfiable {
+			with_predecessor++
+		} else {
+			orphans++
+		}
+	}
+	if with_predecessor == 0 || orphans == 0 {
+		t.Errorf("expected both satisfiable and unsatisfiable ends : %d and %d", with_predecessor, orphans)
+	}
+}
+
+func TestWriteDIMACS(t *testing.T) {
+	cases := []struct {
+		name      string
+		clauses   [][]int
+		variables int
+		want      string
+	}{
+		{"no clauses", nil, 4, "p cnf 4 0\n"},
+		{"two clauses", [][]int{{1, -2}, {-3, 2, 4}}, 4, "p cnf 4 2\n1 -2 0\n-3 2 4 0\n"},
+		{"empty clause", [][]int{{}}, 1, "p cnf 1 1\n0\n"},
+	}
+	for _, c := range cases {
+		var buf strings.Builder
+		if err := WriteDIMACS(&buf, c.clauses, c.variables); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if buf.String() != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, buf.String(), c.want)
+		}
+	}
+
+	// ReverseCNF's clauses for a blinker, one line each after the header
+	clauses, _ := (&LifeProblem{steps: 1, end: board_from_rows(5, 5, "", "", "-XXX")}).ReverseCNF()
+	var buf strings.Builder
+	WriteDIMACS(&buf, clauses, 25)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if lines[0] != fmt.Sprintf("p cnf 25 %d", len(clauses)) || len(lines) != len(clauses)+1 {
+		t.Errorf("header %q with %d clause lines, for %d clauses", lines[0], len(lines)-1, len(clauses))
+	}
+}
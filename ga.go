@@ -14,6 +14,15 @@ type Individual struct {
 	start *Board_BoolPacked
 	diff  *Board_BoolPacked
 	fitness int  // higher is better, no particular scale
+	evaluated bool // fitness (and diff) are up to date with start : cleared whenever start changes
+}
+
+// Take over another individual's board, along with its cached evaluation
+func (i *Individual) CopyFrom(other *Individual) {
+	i.start.CopyFrom(other.start)
+	i.diff.CopyFrom(other.diff)
+	i.fitness = other.fitness
+	i.evaluated = other.evaluated
 }
 
 /*
@@ -37,6 +46,11 @@ type Population struct {
 	crossover_pct int // (0..100)
 	
 	transition_collection *TransitionCollectionList
+	
+	rng *rand.Rand // Source of all the randomness in selection/mutation/crossover
+	
+	pinned_mask   *Board_BoolPacked // Optional : cells that mutation/crossover must leave alone...
+	pinned_values *Board_BoolPacked // ... and the values they are held at
 }
 
 func NewPopulation(size int, radius int, target *Board_BoolPacked, tc *TransitionCollectionList) *Population {
@@ -44,8 +58,8 @@ func NewPopulation(size int, radius int, target *Board_BoolPacked, tc *Transitio
 	ind := make([]*Individual, size)
 	for i:=0; i<size; i++ {
 		ind[i] = &Individual{ 
-			                  start:NewBoard_BoolPacked(board_width, board_height), 
-			                  diff: NewBoard_BoolPacked(board_width, board_height), 
+			                  start:NewBoard_BoolPacked(target.w, target.h), 
+			                  diff: NewBoard_BoolPacked(target.w, target.h), 
 		                      fitness:0,
 		                    }
 	}
@@ -62,7 +76,33 @@ func NewPopulation(size int, radius int, target *Board_BoolPacked, tc *Transitio
 		mutation_radius:radius,
 		
 		crossover_pct:30*1,
+		
+		rng:rand_global,
+	}
+}
+
+// NewPopulationCompact is the same as NewPopulation, except that the individuals, their boards, and all the 
+// boards' packed rows each live in one contiguous allocation (rather than four small ones per individual).
+// Boards are only ever updated in place (CopyFrom etc reuse the rows), so they stay in the arena
+func NewPopulationCompact(size int, radius int, target *Board_BoolPacked, tc *TransitionCollectionList) *Population {
+	rows := target.h + 2
+	arena := make([]int32, 2*size*rows)
+	boards := make([]Board_BoolPacked, 2*size)
+	individuals := make([]Individual, size)
+
+	ind := make([]*Individual, size)
+	for i := range boards {
+		offset := i * rows
+		boards[i] = Board_BoolPacked{s: arena[offset : offset+rows : offset+rows], w: target.w, h: target.h}
+	}
+	for i := range ind {
+		individuals[i] = Individual{start: &boards[2*i], diff: &boards[2*i+1]}
+		ind[i] = &individuals[i]
 	}
+
+	pop := NewPopulation(0, radius, target, tc) // For all the other settings
+	pop.individual = ind
+	return pop
 }
 
 func (p *Population) OrderIndividualsBasedOnFitness(i_1, i_2 *Individual) (*Individual,*Individual) {  
@@ -86,17 +126,17 @@ func (p *Population) OrderIndividualsBasedOnFitness(i_1, i_2 *Individual) (*Indi
 
 func (p *Population) PickIndividualWithPressure() *Individual {  
 	// Pick two individuals at random from population
-	i_1_pos := rand.Intn(len(p.individual))
+	i_1_pos := p.rng.Intn(len(p.individual))
 	i_1 := p.individual[i_1_pos]
 	
-	i_2_pos := rand.Intn(len(p.individual))
+	i_2_pos := p.rng.Intn(len(p.individual))
 	i_2 := p.individual[i_2_pos]
 	
 	i_1, i_2 = p.OrderIndividualsBasedOnFitness(i_1, i_2)
 	
 	// if pct< a threshold, pick the better one
 	i_chosen := i_1
-	if rand.Intn(100) > p.pressure_pct { // i.e. only sometimes do the opposite
+	if p.rng.Intn(100) > p.pressure_pct { // i.e. only sometimes do the opposite
 		i_chosen = i_2
 	}
 	//fmt.Printf("Individuals {%d:%d} Fitnesses : {%d:%d} -> %d\n", i_1_pos, i_2_pos, i_1.fitness, i_2.fitness, i_chosen.fitness)
@@ -116,39 +156,39 @@ func (pop *Population) GenerationAfter(prev *Population) {
 	// Fill in every slot
 	for counter, individual := range pop.individual {
 		if counter==0 { // Reserve position 0 for a copy of the previous generation's best individual
-			best_individual := prev.BestIndividual()
-			individual.start.CopyFrom(best_individual.start)
-			individual.fitness = best_individual.fitness
+			individual.CopyFrom(prev.BestIndividual()) // Unchanged, so no need to re-evaluate it
 			continue
 		}
 		
-		choser := rand.Intn(100)
+		choser := pop.rng.Intn(100)
 		if 0<=choser && choser < pop.crossover_pct { 
 			// Do a 'crossover copy' from two individuals in previous population to this one
 			parent_1 := prev.PickIndividualWithPressure()
 			parent_2 := prev.PickIndividualWithPressure()
-			individual.start.CrossoverFrom(parent_1.start, parent_2.start)
+			individual.start.CrossoverFromR(parent_1.start, parent_2.start, pop.rng)
+			individual.evaluated = false
 		} else { // Do a simple copy, with the possibility of mutation (below)
 			i_chosen := prev.PickIndividualWithPressure()
-			individual.start.CopyFrom(i_chosen.start)
+			individual.CopyFrom(i_chosen)
 			if pop.crossover_pct<=choser && choser < (pop.crossover_pct + pop.mutation_pct) {
+				individual.evaluated = false
 				//individual.start.MutateRadiusBits(pop.mutation_loop_pct, pop.mutation_radius) // % do additional mutation, radius of action
 				
 				x,y := -1,-1
-				if rand.Intn(100)>20 {
+				if pop.rng.Intn(100)>20 {
 					// For this individual, pick a position in the diff
-					x,y = i_chosen.diff.RandomBitPosition()
+					x,y = i_chosen.diff.RandomBitPositionR(pop.rng)
 				} else {
 					// For this individual, pick a position in the target, just for a change
-					x,y = pop.target.RandomBitPosition()
+					x,y = pop.target.RandomBitPositionR(pop.rng)
 				}
 				
 				if x>=0 && y>=0 {
 					// Offset by a little bit...
 					if true {
 						//fmt.Printf("target_error@(%2d,%2d):\n", x,y)
-						x = CoordWithinRadius(x, i_chosen.diff.w, pop.mutation_radius/2+1)
-						y = CoordWithinRadius(y, i_chosen.diff.h, pop.mutation_radius/2+1)
+						x = CoordWithinRadiusR(x, i_chosen.diff.w, pop.mutation_radius/2+1, pop.rng)
+						y = CoordWithinRadiusR(y, i_chosen.diff.h, pop.mutation_radius/2+1, pop.rng)
 					}
 				} else {
 					// There are no errors...  So we don't have a basis for complaining, really
@@ -157,22 +197,24 @@ func (pop *Population) GenerationAfter(prev *Population) {
 					if true {
 						//fmt.Printf("No errors to mutate around : Try using the target instead of the diff\n")
 						//fmt.Println(i_chosen.start) // Check
-						x,y = pop.target.RandomBitPosition()
+						x,y = pop.target.RandomBitPositionR(pop.rng)
 						//fmt.Println("*** Isn't the end image DEFINED to be non-blank? ***")
 					}
 					
 					if false {
 						//fmt.Printf("No errors to mutate around : Try zeroing out bits in the start\n")
-						individual.start.MutateMask(individual.start, pop.mutation_loop_pct, 0) // % do additional mutation, radius of action
+						individual.start.MutateMaskR(individual.start, pop.mutation_loop_pct, 0, pop.rng) // % do additional mutation, radius of action
 						x,y = -1,-1 // Don't do the overlay thing
 					}
 				}
-				if x>=0 && y>=0 {
+				if x>=0 && y>=0 && pop.transition_collection==nil {
+					individual.start.Set(x,y, individual.start.isSet(x,y)==false) // No transitions loaded : Just flip the bit
+				} else if x>=0 && y>=0 {
 					end := pop.target.MakePatch(x,y)
 					//fmt.Printf("Examining patch(%8d) from target @(%2d,%2d):\n", int(end), x,y)
 					//fmt.Print(end)
 					
-					start_random := pop.transition_collection.GetRandomEntry_OrientationCompensated(end)
+					start_random := pop.transition_collection.GetRandomEntry_OrientationCompensatedR(end, pop.rng)
 					if start_random>=0 { // Yes - we have an overlay to try...
 						//fmt.Print("Suggested Start :\n")
 						//fmt.Print(start_random)
@@ -188,12 +230,12 @@ func (pop *Population) GenerationAfter(prev *Population) {
 						
 						if false {
 							//fmt.Printf("Introducing random noise\n")
-							individual.start.MutateMask(individual.start, pop.mutation_loop_pct, pop.mutation_radius)
+							individual.start.MutateMaskR(individual.start, pop.mutation_loop_pct, pop.mutation_radius, pop.rng)
 						}
 						
 						if false {
 							//fmt.Printf("Introducing random zeroing\n")
-							individual.start.MutateMask(individual.start, pop.mutation_loop_pct, 0) // % do additional mutation, radius of action
+							individual.start.MutateMaskR(individual.start, pop.mutation_loop_pct, 0, pop.rng) // % do additional mutation, radius of action
 						}
 					}
 				}
@@ -201,10 +243,186 @@ func (pop *Population) GenerationAfter(prev *Population) {
 			}
 		}
 
-		individual.fitness = 0
+		pop.ApplyPinned(individual.start)
+		if !individual.evaluated {
+			individual.fitness = 0
+		}
 	}
 }
 
+// Evaluate sets the fitness (-mismatch after steps against the target) of every individual not already evaluated.
+// Returns the number of forward iterations actually done
+func (pop *Population) Evaluate(steps int, l *BoardIterator) int {
+	count := 0
+	for _, individual := range pop.individual {
+		if individual.evaluated {
+			continue
+		}
+		l.current.CopyFrom(individual.start)
+		l.Iterate(steps)
+		individual.fitness = -l.current.CompareTo(pop.target, individual.diff)
+		individual.evaluated = true
+		count++
+	}
+	return count
+}
+
+// Force the pinned cells (if any) of b back to their pinned values
+func (pop *Population) ApplyPinned(b *Board_BoolPacked) { // OPTIMIZED FOR BoolPacked
+	if pop.pinned_mask == nil {
+		return
+	}
+	for y := 1; y <= b.h; y++ {
+		mask := pop.pinned_mask.s[y]
+		b.s[y] = (b.s[y] &^ mask) | (pop.pinned_values.s[y] & mask)
+	}
+}
+
+// Settings for SolveGA : zero values get sensible defaults
+type GAConfig struct {
+	PopSize     int
+	Generations int
+	Seed        int64
+	Rand        *rand.Rand // Optional : the generator to use instead of one seeded from Seed
+	
+	Initial     *Board_BoolPacked // Every individual starts as this (default : the end board)
+	PinnedMask  *Board_BoolPacked // Cells set here are never changed from their value in Initial
+	Transitions *TransitionCollectionList // Optional : without these, mutation just flips bits
+	
+	CompactPopulation bool // Keep all the individuals' boards in one arena (see NewPopulationCompact)
+}
+
+// SolveGA runs the GA for a single end board, returning the best start found and its forward mismatch
+func SolveGA(end *Board_BoolPacked, steps int, cfg GAConfig) (*Board_BoolPacked, int) {
+	if cfg.PopSize < 2 {
+		cfg.PopSize = 100
+	}
+	if cfg.Generations < 1 {
+		cfg.Generations = 100
+	}
+	if cfg.Initial == nil {
+		cfg.Initial = end
+	}
+	rng := cfg.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(cfg.Seed))
+	}
+
+	var pop, p_temp *Population
+	if cfg.CompactPopulation {
+		pop    = NewPopulationCompact(cfg.PopSize, steps, end, cfg.Transitions)
+		p_temp = NewPopulationCompact(cfg.PopSize, steps, end, cfg.Transitions)
+	} else {
+		pop    = NewPopulation(cfg.PopSize, steps, end, cfg.Transitions)
+		p_temp = NewPopulation(cfg.PopSize, steps, end, cfg.Transitions)
+	}
+	for _, p := range []*Population{pop, p_temp} {
+		p.rng = rng
+		p.pinned_mask, p.pinned_values = cfg.PinnedMask, cfg.Initial
+	}
+	for _, individual := range pop.individual {
+		individual.start.CopyFrom(cfg.Initial)
+	}
+
+	l := NewBoardIterator(end.w, end.h)
+	pop.Evaluate(steps, l)
+	for gen := 0; gen < cfg.Generations && pop.BestIndividual().fitness < 0; gen++ {
+		p_temp.GenerationAfter(pop)
+		pop, p_temp = p_temp, pop // Switcheroo to advance to next population
+		pop.Evaluate(steps, l)
+	}
+
+	best := pop.BestIndividual()
+	result := NewBoard_BoolPacked(end.w, end.h)
+	result.CopyFrom(best.start)
+	return result, -best.fitness
+}
+
+// SolveGA evolves candidate starts for the problem (cell-flip mutation, and crossover that splices a rectangle 
+// of one parent into the other), scored by the forward mismatch against p.end, and returns the best found.
+// Deterministic for a given r
+func (p *LifeProblem) SolveGA(popSize, generations int, r *rand.Rand) *Board_BoolPacked {
+	start, _ := SolveGA(p.end, p.steps, GAConfig{PopSize: popSize, Generations: generations, Rand: r})
+	return start
+}
+
+// PopulationSizeSweep runs SolveGA on the problem once for each population size (everything else, including
+// the seed, as in cfg), and reports the final forward mismatch for each : To see where bigger stops being better
+func PopulationSizeSweep(problem LifeProblem, sizes []int, cfg GAConfig) map[int]int {
+	mismatches := make(map[int]int)
+	for _, size := range sizes {
+		cfg.PopSize = size
+		_, mismatches[size] = SolveGA(problem.end, problem.steps, cfg)
+	}
+	return mismatches
+}
+
+// SolveMiddleOut splits a long reversal in two : first a GA finds a board (steps/2) generations before the 
+// middle of the trajectory, i.e. one that leads to 'end', and then a second GA reverses the rest of the way to that.
+// Each GA only has to see half as far ahead, but this is an approximation : the middle board is just one plausible
+// predecessor, and any mismatch it has against 'end' is inherited by the second leg.  So the result is then used
+// to seed a final full-horizon GA, which can only improve on it (its best individual is kept).
+// cfg.Initial and cfg.PinnedMask describe the start, so don't apply to the first leg
+func SolveMiddleOut(end *Board_BoolPacked, steps int, cfg GAConfig) *Board_BoolPacked {
+	if steps < 2 {
+		start, _ := SolveGA(end, steps, cfg)
+		return start
+	}
+	first := cfg
+	first.Initial, first.PinnedMask = nil, nil
+	middle, _ := SolveGA(end, steps-steps/2, first)
+
+	start, _ := SolveGA(middle, steps/2, cfg)
+
+	final := cfg
+	final.Initial = start // Any pinned cells already hold their cfg.Initial values
+	start, _ = SolveGA(end, steps, final)
+	return start
+}
+
+// SolveEnsembleSeeds runs SolveGA once per seed (otherwise as cfg, but cfg.Rand is ignored : each run gets
+// its own generator from its seed), and majority-votes the starts cell by cell (a tie leaves the cell dead).
+// A vote can split the difference between runs that each found a different but self-consistent start,
+// so if any single run forward-matches 'end' better than the vote, that run is returned instead
+func SolveEnsembleSeeds(end *Board_BoolPacked, steps int, cfg GAConfig, seeds []int64) *Board_BoolPacked {
+	if len(seeds) == 0 {
+		seeds = []int64{cfg.Seed}
+	}
+	problem := LifeProblem{end: end, steps: steps}
+	votes := make([][]int, end.h)
+	for y := range votes {
+		votes[y] = make([]int, end.w)
+	}
+
+	var best_run *Board_BoolPacked
+	best_run_mismatch := -1
+	for _, seed := range seeds {
+		cfg.Seed, cfg.Rand = seed, nil
+		start, mismatch := SolveGA(end, steps, cfg)
+		if best_run == nil || mismatch < best_run_mismatch {
+			best_run, best_run_mismatch = start, mismatch
+		}
+		for y := 0; y < end.h; y++ {
+			for x := 0; x < end.w; x++ {
+				if start.isSet(x, y) {
+					votes[y][x]++
+				}
+			}
+		}
+	}
+
+	voted := NewBoard_BoolPacked(end.w, end.h)
+	for y := 0; y < end.h; y++ {
+		for x := 0; x < end.w; x++ {
+			voted.Set(x, y, votes[y][x]*2 > len(seeds))
+		}
+	}
+	if _, mismatch := problem.Verify(voted); mismatch > best_run_mismatch {
+		return best_run
+	}
+	return voted
+}
+
 type IndividualResult struct {
 	individual *Individual
 	mismatch_from_true_start_initial, mismatch_from_true_start_final int
@@ -244,48 +462,33 @@ func create_solution(problem LifeProblem, lps *LifeProblemSet) *IndividualResult
 	iter_max  := 2000
 	iter_last := 0
 	for iter:=0; iter<iter_max; iter++ {
-		// Evaluate fitness of every individual in pop
-		for i, individual := range pop.individual {
-			l.current.CopyFrom(individual.start)
-			
-			mismatch_from_true_start:=-999 // NB: Don't use this in fitness calculations!!
-			if lps.is_training {
-				diff     := NewBoard_BoolPacked(board_width, board_height)
-				mismatch_from_true_start = l.current.CompareTo(problem.start, diff)
-				
-				if i==0 { // NB: Best individual is always in [0] (forced there in GenerationAfter)
-					mismatch_from_true_start_latest  = mismatch_from_true_start
-					if iter == 0 { 
-						mismatch_from_true_start_initial  = mismatch_from_true_start
-					}
-				}
+		// Evaluate fitness of every individual in pop (those carried over unchanged keep their cached fitness)
+		pop.Evaluate(problem.steps, l)
+		
+		// NB: Best individual is always in [0] (forced there in GenerationAfter)
+		mismatch_from_true_end_latest = -pop.individual[0].fitness
+		if iter == 0 { 
+			mismatch_from_true_end_initial = mismatch_from_true_end_latest
+		}
+		
+		// Bookkeeping against the true start : NB: Don't use this in fitness calculations!!
+		if lps.is_training {
+			mismatch_from_true_start_latest = pop.individual[0].start.CompareTo(problem.start, nil)
+			if iter == 0 { 
+				mismatch_from_true_start_initial = mismatch_from_true_start_latest
 			}
-			
-			l.Iterate(problem.steps)
-			
-			// This is 'allowed' since we know the end result, and can store the diff
-			mismatch_from_true_end := l.current.CompareTo(problem.end, individual.diff)
-			
-			if i==0 { // NB: Best individual is always in [0] (forced there in GenerationAfter)
-				mismatch_from_true_end_latest  = mismatch_from_true_end
-				if iter == 0 { 
-					mismatch_from_true_end_initial  = mismatch_from_true_end
+		}
+		
+		if iter % checkpoints == 0 {
+			for i:=0; i<3 && i<len(pop.individual); i++ {
+				mismatch_from_true_start:=-999
+				if lps.is_training {
+					mismatch_from_true_start = pop.individual[i].start.CompareTo(problem.start, nil)
 				}
+				fmt.Printf("%4d.%3d : Mismatch vs true {start,end} = {%3d,%3d}\n", iter, i, mismatch_from_true_start, -pop.individual[i].fitness) // , individual.start
 			}
-			
-			// This is a lower factor pressure, but good to have too
-			count_on := individual.start.CompareTo(board_empty, nil)
-			
-			individual.fitness = -mismatch_from_true_end  -count_on*0
-			//individual.fitness = -mismatch_from_true_end*4 -count_on*1
-			//individual.fitness = -mismatch_from_true_end*problem.steps -count_on*1
-			
-			if i<3 && (iter % checkpoints == 0) {
-				fmt.Printf("%4d.%3d : Mismatch vs true {start,end} = {%3d,%3d}\n", iter, i, mismatch_from_true_start, mismatch_from_true_end) // , individual.start
-			}
-			
-			iter_last=iter
 		}
+		iter_last=iter
 		
 		best_individual = pop.BestIndividual()
 		//fmt.Printf("%4d.best: Mismatch vs true {start,end} = {???,%3d}\n", iter, best_individual.fitness)
@@ -396,7 +599,10 @@ func pick_problems_from_list_and_solve_them(steps int, list_position int) {
 func solve_list_of_problems_and_write_to_db(steps int, problem_list []int, is_training bool) {  
 	var kaggle LifeProblemSet
 	
-	kaggle.load_csv(is_training, problem_list)
+	if err := kaggle.LoadCSV(is_training, problem_list, board_width, board_height); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 
 	// Now ensure that the transition_collection is valid for this step size
 	kaggle.load_transition_collection(steps)
@@ -0,0 +1,52 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"math/rand"
+)
+
+// Shared fixtures for the _test.go files
+
+// board_from_rows builds a w x h board from rows of '-' (dead) and 'X' (alive), rows may be shorter than w
+func board_from_rows(w, h int, rows ...string) *Board_BoolPacked {
+	b := NewBoard_BoolPacked(w, h)
+	for y, row := range rows {
+		for x, c := range row {
+			if c == 'X' {
+				b.Set(x, y, true)
+			}
+		}
+	}
+	return b
+}
+
+// random_board fills a w x h board to roughly pct density, the same board for the same seed
+func random_board(w, h int, pct float32, seed int64) *Board_BoolPacked {
+	b := NewBoard_BoolPacked(w, h)
+	r := rand.New(rand.NewSource(seed))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			b.Set(x, y, r.Float32() < pct)
+		}
+	}
+	return b
+}
+
+// forward returns a new board 'steps' generations on from b (b is left untouched)
+func forward(b *Board_BoolPacked, steps int) *Board_BoolPacked {
+	cur := NewBoard_BoolPacked(b.w, b.h)
+	cur.CopyFrom(b)
+	next := NewBoard_BoolPacked(b.w, b.h)
+	for i := 0; i < steps; i++ {
+		cur.Iterate(next)
+		cur, next = next, cur
+	}
+	return cur
+}
+
+// problem_from_start builds a training problem whose end is start run forward 'steps' generations
+func problem_from_start(id int, start *Board_BoolPacked, steps int) LifeProblem {
+	return LifeProblem{id: id, start: start, end: forward(start, steps), steps: steps}
+}
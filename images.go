@@ -0,0 +1,285 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+	"os"
+)
+
+// PNGOptions controls the rendering of a single board by SaveBoardPNG
+type PNGOptions struct {
+	Scale     int // Pixels per cell (values below 1 mean 1)
+	GridEvery int // Draw a faint grid line every GridEvery cells (0 disables the grid, as does a Scale below 2)
+}
+
+var png_live_color = color.Gray{255}
+var png_dead_color = color.Gray{0}
+var png_grid_color = color.Gray{96}
+
+// Same convention as DrawStats : live cells are white, dead cells are black
+func RenderBoard(b *Board_BoolPacked, opts PNGOptions) *image.RGBA {
+	scale := opts.Scale
+	if scale < 1 {
+		scale = 1
+	}
+	im := image.NewRGBA(image.Rect(0, 0, b.w*scale, b.h*scale))
+	for y := 0; y < b.h; y++ {
+		for x := 0; x < b.w; x++ {
+			c := png_dead_color
+			if b.isSet(x, y) {
+				c = png_live_color
+			}
+			for py := 0; py < scale; py++ {
+				for px := 0; px < scale; px++ {
+					im.Set(x*scale+px, y*scale+py, c)
+				}
+			}
+		}
+	}
+
+	if opts.GridEvery > 0 && scale > 1 {
+		// Lines run along the top/left pixel edge of every GridEvery'th cell (at scale 1, that is the whole cell)
+		bounds := im.Bounds()
+		for x := opts.GridEvery; x < b.w; x += opts.GridEvery {
+			for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+				im.Set(x*scale, py, png_grid_color)
+			}
+		}
+		for y := opts.GridEvery; y < b.h; y += opts.GridEvery {
+			for px := bounds.Min.X; px < bounds.Max.X; px++ {
+				im.Set(px, y*scale, png_grid_color)
+			}
+		}
+	}
+	return im
+}
+
+// SaveBoardPNG writes a single board out as a PNG
+func SaveBoardPNG(path string, b *Board_BoolPacked, opts PNGOptions) error {
+	w, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return png.Encode(w, RenderBoard(b, opts))
+}
+
+var heatmap_cold = color.RGBA{0, 0, 255, 255}
+var heatmap_hot = color.RGBA{255, 0, 0, 255}
+
+// Blue (p=0) through white (p=0.5) to red (p=1)
+func heatmap_color(p float64) color.RGBA {
+	if p < 0 {
+		p = 0
+	} else if p > 1 {
+		p = 1
+	}
+	if p < 0.5 {
+		v := uint8(p * 2 * 255)
+		return color.RGBA{v, v, 255, 255}
+	}
+	v := uint8((1 - p) * 2 * 255)
+	return color.RGBA{255, v, v, 255}
+}
+
+// RenderProbHeatmap draws per-cell probabilities (indexed [y][x]) as a heatmap, scale pixels per cell
+func RenderProbHeatmap(prob [][]float64, scale int) *image.RGBA {
+	if scale < 1 {
+		scale = 1
+	}
+	w := 0
+	if len(prob) > 0 {
+		w = len(prob[0])
+	}
+	im := image.NewRGBA(image.Rect(0, 0, w*scale, len(prob)*scale))
+	for y, row := range prob {
+		for x, p := range row {
+			c := heatmap_color(p)
+			for py := 0; py < scale; py++ {
+				for px := 0; px < scale; px++ {
+					im.SetRGBA(x*scale+px, y*scale+py, c)
+				}
+			}
+		}
+	}
+	return im
+}
+
+// SaveProbHeatmapPNG writes the probability board out as a blue-white-red heatmap PNG
+func SaveProbHeatmapPNG(path string, prob [][]float64, scale int) error {
+	w, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return png.Encode(w, RenderProbHeatmap(prob, scale))
+}
+
+// DiffImage highlights in red every pixel that differs between two renderings (e.g. two contact sheets).
+// Matching pixels are left transparent
+func DiffImage(a, b *image.RGBA) (*image.RGBA, error) {
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return nil, fmt.Errorf("image sizes differ : %v vs %v", a.Bounds().Size(), b.Bounds().Size())
+	}
+	diff := image.NewRGBA(image.Rect(0, 0, a.Bounds().Dx(), a.Bounds().Dy()))
+	red := color.RGBA{255, 0, 0, 255}
+	for y := 0; y < diff.Bounds().Dy(); y++ {
+		for x := 0; x < diff.Bounds().Dx(); x++ {
+			if a.RGBAAt(a.Bounds().Min.X+x, a.Bounds().Min.Y+y) != b.RGBAAt(b.Bounds().Min.X+x, b.Bounds().Min.Y+y) {
+				diff.SetRGBA(x, y, red)
+			}
+		}
+	}
+	return diff, nil
+}
+
+// RenderDisagreement draws how split an ensemble of candidate boards is on each cell :
+// black where they are unanimous, rising to white where they split 50/50
+func RenderDisagreement(candidates []*Board_BoolPacked, scale int) *image.RGBA {
+	if scale < 1 {
+		scale = 1
+	}
+	w, h := candidates[0].w, candidates[0].h
+	im := image.NewRGBA(image.Rect(0, 0, w*scale, h*scale))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			alive := 0
+			for _, c := range candidates {
+				if c.isSet(x, y) {
+					alive++
+				}
+			}
+			p := float64(alive) / float64(len(candidates))
+			split := 1 - 2*math.Abs(p-0.5) // 0 when unanimous, 1 at 50/50
+			c := color.Gray{uint8(split*255 + 0.5)}
+			for py := 0; py < scale; py++ {
+				for px := 0; px < scale; px++ {
+					im.Set(x*scale+px, y*scale+py, c)
+				}
+			}
+		}
+	}
+	return im
+}
+
+// SaveDisagreementPNG writes the ensemble's per-cell disagreement out as a PNG (see RenderDisagreement)
+func SaveDisagreementPNG(path string, candidates []*Board_BoolPacked, scale int) error {
+	if len(candidates) == 0 {
+		return fmt.Errorf("no candidates to compare")
+	}
+	for _, c := range candidates[1:] {
+		if c.w != candidates[0].w || c.h != candidates[0].h {
+			return fmt.Errorf("candidate sizes differ : %dx%d vs %dx%d", candidates[0].w, candidates[0].h, c.w, c.h)
+		}
+	}
+	w, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return png.Encode(w, RenderDisagreement(candidates, scale))
+}
+
+// Frames have the same 2 pixel border as ImageSet
+var gif_palette = color.Palette{
+	color.RGBA{255, 255, 255, 255}, // Dead
+	color.RGBA{0, 0, 0, 255},       // Live
+	color.RGBA{98, 166, 255, 255},  // Border
+}
+
+// WriteAnimatedGIF writes one frame per board (e.g. from BoardIterator.Record), live cells black on white.
+// delay is the time each frame is shown, in 100ths of a second
+func WriteAnimatedGIF(path string, boards []*Board_BoolPacked, delay int) error {
+	if len(boards) == 0 {
+		return fmt.Errorf("no boards to animate")
+	}
+	w, h := boards[0].w, boards[0].h
+	anim := &gif.GIF{}
+	for _, b := range boards {
+		if b.w != w || b.h != h {
+			return fmt.Errorf("frame sizes differ : %dx%d vs %dx%d", w, h, b.w, b.h)
+		}
+		frame := image.NewPaletted(image.Rect(0, 0, w+4, h+4), gif_palette)
+		for i := range frame.Pix {
+			frame.Pix[i] = 2
+		}
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				c := uint8(0)
+				if b.isSet(x, y) {
+					c = 1
+				}
+				frame.SetColorIndex(x+2, y+2, c)
+			}
+		}
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return gif.EncodeAll(f, anim)
+}
+
+// The default luminance (0..255) separating live from dead cells in LoadPNG
+const png_live_luminance = 128
+
+// PNGLoadOptions controls how LoadPNGWithOptions decides which pixels are live cells
+type PNGLoadOptions struct {
+	Threshold uint8 // Luminance separating live from dead (0 means png_live_luminance)
+	LiveLight bool  // Live cells are at least Threshold (as RenderBoard draws them), rather than darker than it
+}
+
+// LoadPNG reads a hand-drawn pattern : dark ink (luminance below png_live_luminance) is alive, and light or
+// transparent pixels are dead.  NB: RenderBoard and ImageSet draw live cells white, so read their output
+// back with LoadPNGWithOptions and LiveLight set
+func (f *Board_BoolPacked) LoadPNG(r io.Reader) error {
+	return f.LoadPNGWithOptions(r, PNGLoadOptions{})
+}
+
+// LoadPNGWithOptions is LoadPNG with a choice of threshold and polarity (transparent pixels are always dead).
+// The image is sampled at the centre of each cell, so any size stretches to fit the board, and a board with
+// no size yet takes the image's
+func (f *Board_BoolPacked) LoadPNGWithOptions(r io.Reader, opts PNGLoadOptions) error {
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = png_live_luminance
+	}
+	im, err := png.Decode(r)
+	if err != nil {
+		return err
+	}
+	bounds := im.Bounds()
+	if bounds.Empty() {
+		return fmt.Errorf("PNG is empty")
+	}
+	if f.w == 0 && f.h == 0 {
+		if bounds.Dx() > 30 {
+			return fmt.Errorf("PNG is %d pixels wide, too wide for a board of its own size", bounds.Dx())
+		}
+		*f = *NewBoard_BoolPacked(bounds.Dx(), bounds.Dy())
+	}
+
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < f.w; x++ {
+			px := bounds.Min.X + (2*x+1)*bounds.Dx()/(2*f.w)
+			py := bounds.Min.Y + (2*y+1)*bounds.Dy()/(2*f.h)
+			c := im.At(px, py)
+			_, _, _, alpha := c.RGBA()
+			gray := color.GrayModel.Convert(c).(color.Gray)
+			f.Set(x, y, alpha >= 0x8000 && (gray.Y >= threshold) == opts.LiveLight)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,406 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func same_color(a, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
+}
+
+func decode_png(t *testing.T, path string) image.Image {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	im, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return im
+}
+
+func TestSaveBoardPNGGrid(t *testing.T) {
+	full := random_board(board_width, board_height, 1, 1)
+	mixed := random_board(board_width, board_height, 0.5, 2)
+	cases := []struct {
+		name      string
+		board     *Board_BoolPacked
+		gridEvery int
+		scale     int
+	}{
+		{"no grid", full, 0, 4},
+		{"grid every 5 on live cells", full, 5, 4},
+		{"grid every 5 on dead cells", NewBoard_BoolPacked(board_width, board_height), 5, 4},
+		{"grid every 3 at scale 2", mixed, 3, 2},
+		{"no grid at scale 1 (it would hide cells)", mixed, 7, 1},
+	}
+	dir := t.TempDir()
+	for _, c := range cases {
+		path := filepath.Join(dir, "grid.png")
+		if err := SaveBoardPNG(path, c.board, PNGOptions{Scale: c.scale, GridEvery: c.gridEvery}); err != nil {
+			t.Fatal(err)
+		}
+		im := decode_png(t, path)
+
+		on_grid := func(cell int) bool { return c.scale > 1 && c.gridEvery > 0 && cell > 0 && cell%c.gridEvery == 0 }
+		for y := 0; y < c.board.h; y++ {
+			for x := 0; x < c.board.w; x++ {
+				var cell_color color.Color = png_dead_color
+				if c.board.isSet(x, y) {
+					cell_color = png_live_color
+				}
+				// Grid lines run along the top/left pixel edge of a cell
+				want := cell_color
+				if on_grid(x) || on_grid(y) {
+					want = png_grid_color
+				}
+				if got := im.At(x*c.scale, y*c.scale); !same_color(got, want) {
+					t.Fatalf("%s: corner pixel of cell (%d,%d) is %v, want %v", c.name, x, y, got, want)
+				}
+				if c.scale > 1 {
+					if got := im.At(x*c.scale+1, y*c.scale+1); !same_color(got, cell_color) {
+						t.Fatalf("%s: inside of cell (%d,%d) is %v, want the cell colour", c.name, x, y, got)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDiffImage(t *testing.T) {
+	base := RenderBoard(random_board(10, 8, 0.4, 3), PNGOptions{Scale: 2})
+	changed := func(x, y int) *image.RGBA {
+		im := image.NewRGBA(base.Bounds())
+		copy(im.Pix, base.Pix)
+		im.SetRGBA(x, y, color.RGBA{10, 20, 30, 255})
+		return im
+	}
+	red := color.RGBA{255, 0, 0, 255}
+	cases := []struct {
+		name    string
+		b       *image.RGBA
+		changed []image.Point
+		wantErr bool
+	}{
+		{"identical", base, nil, false},
+		{"one pixel", changed(5, 3), []image.Point{{5, 3}}, false},
+		{"corner pixel", changed(19, 15), []image.Point{{19, 15}}, false},
+		{"different size", RenderBoard(random_board(10, 8, 0.4, 3), PNGOptions{Scale: 1}), nil, true},
+	}
+	for _, c := range cases {
+		diff, err := DiffImage(base, c.b)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		for y := 0; y < diff.Bounds().Dy(); y++ {
+			for x := 0; x < diff.Bounds().Dx(); x++ {
+				want := color.RGBA{}
+				for _, p := range c.changed {
+					if p.X == x && p.Y == y {
+						want = red
+					}
+				}
+				if got := diff.RGBAAt(x, y); got != want {
+					t.Fatalf("%s: diff pixel (%d,%d) is %v, want %v", c.name, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSaveProbHeatmapPNG(t *testing.T) {
+	prob := [][]float64{
+		{0, 0.5, 1},
+		{1.5, -0.2, 0.25},
+	}
+	cases := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"0 is blue", 0, 0, color.RGBA{0, 0, 255, 255}},
+		{"0.5 is white", 1, 0, color.RGBA{255, 255, 255, 255}},
+		{"1 is red", 2, 0, color.RGBA{255, 0, 0, 255}},
+		{"above 1 clamps to red", 0, 1, color.RGBA{255, 0, 0, 255}},
+		{"below 0 clamps to blue", 1, 1, color.RGBA{0, 0, 255, 255}},
+		{"0.25 is half way to white", 2, 1, color.RGBA{127, 127, 255, 255}},
+	}
+	const scale = 3
+	path := filepath.Join(t.TempDir(), "heatmap.png")
+	if err := SaveProbHeatmapPNG(path, prob, scale); err != nil {
+		t.Fatal(err)
+	}
+	im := decode_png(t, path)
+	if size := im.Bounds().Size(); size.X != 3*scale || size.Y != 2*scale {
+		t.Fatalf("image is %v, want %dx%d", size, 3*scale, 2*scale)
+	}
+	for _, c := range cases {
+		for py := 0; py < scale; py++ {
+			for px := 0; px < scale; px++ {
+				if got := im.At(c.x*scale+px, c.y*scale+py); !same_color(got, c.want) {
+					t.Errorf("%s: pixel is %v, want %v", c.name, got, c.want)
+				}
+			}
+		}
+	}
+}
+
+func TestSaveDisagreementPNG(t *testing.T) {
+	// Cell (1,1) is alive on the first k of the candidates, (2,2) on all of them, and (0,0) on none
+	candidates := func(n, k int) []*Board_BoolPacked {
+		boards := []*Board_BoolPacked{}
+		for i := 0; i < n; i++ {
+			b := board_from_rows(4, 4, "", "", "--X")
+			b.Set(1, 1, i < k)
+			boards = append(boards, b)
+		}
+		return boards
+	}
+	cases := []struct {
+		name       string
+		candidates []*Board_BoolPacked
+		scale      int
+		split      uint8 // Grey level at (1,1)
+	}{
+		{"two of three", candidates(3, 2), 2, 170},
+		{"one of three", candidates(3, 1), 1, 170},
+		{"even split", candidates(4, 2), 3, 255},
+		{"one of four", candidates(4, 1), 1, 128},
+		{"unanimous", candidates(3, 3), 2, 0},
+		{"single candidate", candidates(1, 1), 1, 0},
+	}
+	path := filepath.Join(t.TempDir(), "disagreement.png")
+	for _, c := range cases {
+		if err := SaveDisagreementPNG(path, c.candidates, c.scale); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		im := decode_png(t, path)
+		if im.Bounds().Dx() != 4*c.scale || im.Bounds().Dy() != 4*c.scale {
+			t.Errorf("%s: image is %v, want %dx%d", c.name, im.Bounds(), 4*c.scale, 4*c.scale)
+		}
+		for _, px := range []struct {
+			x, y int
+			want uint8
+		}{{1, 1, c.split}, {2, 2, 0}, {0, 0, 0}} {
+			for _, offset := range []int{0, c.scale - 1} { // Every pixel of the cell
+				got := im.At(px.x*c.scale+offset, px.y*c.scale+offset)
+				if !same_color(got, color.Gray{px.want}) {
+					t.Errorf("%s: cell (%d,%d) is %v, want grey %d", c.name, px.x, px.y, got, px.want)
+				}
+			}
+		}
+	}
+
+	errors := []struct {
+		name       string
+		path       string
+		candidates []*Board_BoolPacked
+	}{
+		{"no candidates", path, nil},
+		{"sizes differ", path, []*Board_BoolPacked{NewBoard_BoolPacked(4, 4), NewBoard_BoolPacked(5, 4)}},
+		{"unwritable path", filepath.Join(t.TempDir(), "absent", "d.png"), candidates(2, 1)},
+	}
+	for _, c := range errors {
+		if err := SaveDisagreementPNG(c.path, c.candidates, 1); err == nil {
+			t.Errorf("%s: no error", c.name)
+		}
+	}
+}
+
+func TestWriteAnimatedGIF(t *testing.T) {
+	cases := []struct {
+		name   string
+		start  *Board_BoolPacked
+		steps  int
+		period int // Frames i and i+period are identical (0 : not checked)
+	}{
+		{"blinker", board_from_rows(5, 5, "", "", "-XXX-"), 2, 2},
+		{"block", board_from_rows(4, 4, "", "-XX", "-XX"), 1, 1},
+		{"glider", board_from_rows(8, 8, "-X", "--X", "XXX"), 4, 0},
+		{"single frame", random_board(6, 3, 0.5, 1), 0, 0},
+	}
+	path := filepath.Join(t.TempDir(), "anim.gif")
+	for _, c := range cases {
+		l := NewBoardIterator(c.start.w, c.start.h)
+		l.current.CopyFrom(c.start)
+		frames := l.Record(c.steps)
+		if len(frames) != c.steps+1 {
+			t.Fatalf("%s: Record(%d) gave %d boards", c.name, c.steps, len(frames))
+		}
+		for i, frame := range frames {
+			if frame.CompareTo(forward(c.start, i), nil) != 0 {
+				t.Errorf("%s: recorded board %d is not generation %d", c.name, i, i)
+			}
+		}
+
+		if err := WriteAnimatedGIF(path, frames, 10); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		anim, err := gif.DecodeAll(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(anim.Image) != len(frames) {
+			t.Fatalf("%s: %d frames in the GIF, want %d", c.name, len(anim.Image), len(frames))
+		}
+		for i, im := range anim.Image {
+			if anim.Delay[i] != 10 {
+				t.Errorf("%s: frame %d delay %d", c.name, i, anim.Delay[i])
+			}
+			if im.Bounds().Dx() != c.start.w+4 || im.Bounds().Dy() != c.start.h+4 {
+				t.Errorf("%s: frame %d is %v, want the board plus a 2 pixel border", c.name, i, im.Bounds())
+			}
+			for y := -2; y < c.start.h+2; y++ {
+				for x := -2; x < c.start.w+2; x++ {
+					want := uint8(2) // Border
+					if x >= 0 && x < c.start.w && y >= 0 && y < c.start.h {
+						want = 0
+						if frames[i].isSet(x, y) {
+							want = 1
+						}
+					}
+					if got := im.ColorIndexAt(x+2, y+2); got != want {
+						t.Fatalf("%s: frame %d pixel for (%d,%d) is %d, want %d", c.name, i, x, y, got, want)
+					}
+				}
+			}
+		}
+		if c.period > 0 && len(anim.Image) > c.period {
+			if !bytes.Equal(anim.Image[0].Pix, anim.Image[c.period].Pix) {
+				t.Errorf("%s: frames 0 and %d differ", c.name, c.period)
+			}
+			if c.period > 1 && bytes.Equal(anim.Image[0].Pix, anim.Image[1].Pix) {
+				t.Errorf("%s: frames 0 and 1 are the same, the oscillation is not visible", c.name)
+			}
+		}
+	}
+
+	errors := []struct {
+		name   string
+		boards []*Board_BoolPacked
+	}{
+		{"no boards", nil},
+		{"sizes differ", []*Board_BoolPacked{NewBoard_BoolPacked(5, 5), NewBoard_BoolPacked(5, 6)}},
+	}
+	for _, c := range errors {
+		if err := WriteAnimatedGIF(path, c.boards, 10); err == nil {
+			t.Errorf("%s: no error", c.name)
+		}
+	}
+}
+
+func TestLoadPNGRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		b    *Board_BoolPacked
+		opts PNGOptions
+	}{
+		{"one pixel per cell", random_board(board_width, board_height, 0.3, 1), PNGOptions{Scale: 1}},
+		{"scaled", random_board(board_width, board_height, 0.5, 2), PNGOptions{Scale: 3}},
+		{"scaled with grid", random_board(board_width, board_height, 0.4, 3), PNGOptions{Scale: 4, GridEvery: 5}},
+		{"small board", board_from_rows(5, 3, "-X", "--X", "XXX"), PNGOptions{Scale: 2}},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, RenderBoard(c.b, c.opts)); err != nil {
+			t.Fatal(err)
+		}
+		loaded := NewBoard_BoolPacked(c.b.w, c.b.h)
+		if err := loaded.LoadPNGWithOptions(bytes.NewReader(buf.Bytes()), PNGLoadOptions{LiveLight: true}); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if d := loaded.HammingDistance(c.b); d != 0 {
+			t.Errorf("%s: loaded board differs from the rendered one in %d cells", c.name, d)
+		}
+
+		// The default (dark is live) reads the same render back inverted
+		inverted := NewBoard_BoolPacked(c.b.w, c.b.h)
+		if err := inverted.LoadPNG(bytes.NewReader(buf.Bytes())); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if d := inverted.HammingDistance(c.b); d != c.b.w*c.b.h {
+			t.Errorf("%s: default polarity agreed with the render in %d cells, want none", c.name, c.b.w*c.b.h-d)
+		}
+	}
+
+	// An unsized board takes the size of a 1:1 render
+	b := random_board(7, 4, 0.5, 4)
+	var buf bytes.Buffer
+	png.Encode(&buf, RenderBoard(b, PNGOptions{}))
+	var unsized Board_BoolPacked
+	if err := unsized.LoadPNGWithOptions(&buf, PNGLoadOptions{LiveLight: true}); err != nil {
+		t.Fatal(err)
+	}
+	if unsized.w != 7 || unsized.h != 4 || unsized.HammingDistance(b) != 0 {
+		t.Errorf("unsized board loaded as %dx%d:\n%s", unsized.w, unsized.h, &unsized)
+	}
+}
+
+func TestLoadPNGThreshold(t *testing.T) {
+	// Pixels of luminance 0, 100, 200 and 255, then a transparent one, in Gray and RGBA images
+	levels := []uint8{0, 100, 200, 255}
+	gray := image.NewGray(image.Rect(0, 0, 5, 1))
+	rgba := image.NewRGBA(image.Rect(0, 0, 5, 1))
+	for x, l := range levels {
+		gray.SetGray(x, 0, color.Gray{l})
+		rgba.Set(x, 0, color.RGBA{l, l, l, 255})
+	}
+	gray.SetGray(4, 0, color.Gray{0}) // Gray has no alpha, so this is black ink
+	rgba.Set(4, 0, color.RGBA{0, 0, 0, 0})
+
+	cases := []struct {
+		name      string
+		opts      PNGLoadOptions
+		want_gray string
+		want_rgba string
+	}{
+		{"default", PNGLoadOptions{}, "XX--X", "XX---"},
+		{"dark, low threshold", PNGLoadOptions{Threshold: 50}, "X---X", "X----"},
+		{"dark, high threshold", PNGLoadOptions{Threshold: 230}, "XXX-X", "XXX--"},
+		{"light", PNGLoadOptions{LiveLight: true}, "--XX-", "--XX-"},
+		{"light, high threshold", PNGLoadOptions{Threshold: 255, LiveLight: true}, "---X-", "---X-"},
+	}
+	for _, c := range cases {
+		for _, in := range []struct {
+			im   image.Image
+			want string
+		}{{gray, c.want_gray}, {rgba, c.want_rgba}} {
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, in.im); err != nil {
+				t.Fatal(err)
+			}
+			b := NewBoard_BoolPacked(5, 1)
+			if err := b.LoadPNGWithOptions(&buf, c.opts); err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			}
+			if b.HammingDistance(board_from_rows(5, 1, in.want)) != 0 {
+				t.Errorf("%s (%T): got %s, want %s", c.name, in.im, b.toCompactString(), in.want)
+			}
+		}
+	}
+}
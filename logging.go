@@ -0,0 +1,154 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// What a solver reports about one problem
+type SolveResult struct {
+	id         int
+	steps      int
+	mismatch   int // Forward mismatch of the prediction against the end board
+	duration   time.Duration
+	method     string
+	confidence float64 // 0..1, how much the solver trusts its answer
+}
+
+// JSONLogger writes one JSON object per line for each SolveResult.  Safe to share between workers
+type JSONLogger struct {
+	mutex  sync.Mutex
+	w      io.Writer
+	method string // Used for results that don't name their own method
+}
+
+func NewJSONLogger(w io.Writer, method string) *JSONLogger {
+	return &JSONLogger{w: w, method: method}
+}
+
+func (logger *JSONLogger) Log(result SolveResult) error {
+	if result.method == "" {
+		result.method = logger.method
+	}
+	line, err := json.Marshal(struct {
+		Id         int     `json:"id"`
+		Steps      int     `json:"steps"`
+		Mismatch   int     `json:"mismatch"`
+		DurationMs float64 `json:"duration_ms"`
+		Method     string  `json:"method"`
+		Confidence float64 `json:"confidence"`
+	}{
+		result.id,
+		result.steps,
+		result.mismatch,
+		float64(result.duration) / float64(time.Millisecond),
+		result.method,
+		result.confidence,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	_, err = logger.w.Write(append(line, '\n'))
+	return err
+}
+
+// SlowestProblems returns the ids of the n results that took longest, slowest first (ties in id order)
+func SlowestProblems(results map[int]SolveResult, n int) []int {
+	ids := []int{}
+	for id := range results {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if results[ids[i]].duration != results[ids[j]].duration {
+			return results[ids[i]].duration > results[ids[j]].duration
+		}
+		return ids[i] < ids[j]
+	})
+	if n < len(ids) {
+		ids = ids[:n]
+	}
+	return ids
+}
+
+// How many of the worst problems WriteRunReport lists
+const run_report_worst = 5
+
+// WriteRunReport writes a plain text summary of a batch run (e.g. LifeProblemSet.results after SolveAll) :
+// overall mean mismatch, exact matches, per-cell accuracy by steps, the worst problems and the total solve time.
+// problems supplies the board sizes (results for ids it doesn't have count as board_width x board_height)
+func WriteRunReport(path string, results map[int]SolveResult, problems *LifeProblemSet) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+
+	total_mismatch, exact, total_cells := 0, 0, 0
+	var total_duration time.Duration
+	step_mismatch, step_cells, step_count := make(map[int]int), make(map[int]int), make(map[int]int)
+	for id, result := range results {
+		cells := board_width * board_height
+		if problem, ok := problems.problem[id]; ok {
+			cells = problem.end.w * problem.end.h
+		}
+		total_mismatch += result.mismatch
+		total_cells += cells
+		total_duration += result.duration
+		if result.mismatch == 0 {
+			exact++
+		}
+		step_mismatch[result.steps] += result.mismatch
+		step_cells[result.steps] += cells
+		step_count[result.steps]++
+	}
+
+	fmt.Fprintf(w, "Problems       : %d\n", len(results))
+	if len(results) > 0 {
+		fmt.Fprintf(w, "Mean mismatch  : %.2f\n", float64(total_mismatch)/float64(len(results)))
+		fmt.Fprintf(w, "Exact matches  : %d (%.1f%%)\n", exact, 100*float64(exact)/float64(len(results)))
+		fmt.Fprintf(w, "Cell accuracy  : %.4f\n", 1-float64(total_mismatch)/float64(total_cells))
+	}
+	fmt.Fprintf(w, "Total runtime  : %v\n", total_duration)
+
+	fmt.Fprintf(w, "\nAccuracy by steps :\n")
+	steps_list := []int{}
+	for steps := range step_count {
+		steps_list = append(steps_list, steps)
+	}
+	sort.Ints(steps_list)
+	for _, steps := range steps_list {
+		fmt.Fprintf(w, "  steps=%d : %4d problems, accuracy %.4f\n", steps, step_count[steps],
+			1-float64(step_mismatch[steps])/float64(step_cells[steps]))
+	}
+
+	fmt.Fprintf(w, "\nWorst problems :\n")
+	ids := []int{}
+	for id := range results {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { // Ties in id order
+		if results[ids[i]].mismatch != results[ids[j]].mismatch {
+			return results[ids[i]].mismatch > results[ids[j]].mismatch
+		}
+		return ids[i] < ids[j]
+	})
+	for i := 0; i < len(ids) && i < run_report_worst; i++ {
+		result := results[ids[i]]
+		fmt.Fprintf(w, "  id[%6d].steps=%d : mismatch=%d, duration=%v\n", ids[i], result.steps, result.mismatch, result.duration)
+	}
+
+	return w.Flush()
+}
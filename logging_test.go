@@ -0,0 +1,210 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLogger(t *testing.T) {
+	cases := []struct {
+		name   string
+		result SolveResult
+		want   map[string]interface{}
+	}{
+		{"named method", SolveResult{id: 7, steps: 3, mismatch: 12, duration: 1500 * time.Microsecond, method: "ga", confidence: 0.97},
+			map[string]interface{}{"id": 7.0, "steps": 3.0, "mismatch": 12.0, "duration_ms": 1.5, "method": "ga", "confidence": 0.97}},
+		{"logger's default method", SolveResult{id: 50001, steps: 1, duration: 2 * time.Second, confidence: 1},
+			map[string]interface{}{"id": 50001.0, "steps": 1.0, "mismatch": 0.0, "duration_ms": 2000.0, "method": "lookup", "confidence": 1.0}},
+	}
+	var buf bytes.Buffer
+	logger := NewJSONLogger(&buf, "lookup")
+	for _, c := range cases {
+		if err := logger.Log(c.result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(cases) {
+		t.Fatalf("%d lines logged, want %d", len(lines), len(cases))
+	}
+	for i, c := range cases {
+		got := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(lines[i]), &got); err != nil {
+			t.Fatalf("%s: line %q isn't JSON : %v", c.name, lines[i], err)
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("%s: fields %v, want %v", c.name, got, c.want)
+		}
+		for k, v := range c.want {
+			if got[k] != v {
+				t.Errorf("%s: %s = %v, want %v", c.name, k, got[k], v)
+			}
+		}
+	}
+}
+
+type failing_writer struct{ writes int }
+
+func (w *failing_writer) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+// SolveAll carries on past logging errors, and reports the first one
+func TestSolveAllLoggerError(t *testing.T) {
+	cases := []struct {
+		name    string
+		w       *failing_writer
+		wantErr bool
+	}{
+		{"no logger", nil, false},
+		{"failing logger", &failing_writer{}, true},
+	}
+	for _, c := range cases {
+		s := random_test_set(10)
+		if c.w != nil {
+			s.logger = NewJSONLogger(c.w, "random")
+		}
+		predictions, err := s.SolveAll(4, 1, random_solver)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: SolveAll() error = %v", c.name, err)
+		}
+		if len(predictions) != 10 || len(s.results) != 10 {
+			t.Errorf("%s: %d predictions and %d results, want 10 of each", c.name, len(predictions), len(s.results))
+		}
+		if c.w != nil && c.w.writes != 10 {
+			t.Errorf("%s: %d log writes, want one per problem", c.name, c.w.writes)
+		}
+	}
+}
+
+func TestSlowestProblems(t *testing.T) {
+	results := map[int]SolveResult{
+		1: {id: 1, duration: 5 * time.Millisecond},
+		2: {id: 2, duration: 9 * time.Millisecond},
+		3: {id: 3, duration: 1 * time.Millisecond},
+		4: {id: 4, duration: 7 * time.Millisecond},
+		5: {id: 5, duration: 5 * time.Millisecond},
+	}
+	cases := []struct {
+		name    string
+		results map[int]SolveResult
+		n       int
+		want    []int
+	}{
+		{"top 3", results, 3, []int{2, 4, 1}},
+		{"ties in id order", results, 4, []int{2, 4, 1, 5}},
+		{"more than there are", results, 10, []int{2, 4, 1, 5, 3}},
+		{"none wanted", results, 0, []int{}},
+		{"no results", map[int]SolveResult{}, 3, []int{}},
+	}
+	for _, c := range cases {
+		if got := SlowestProblems(c.results, c.n); fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	// SolveAll times each problem into s.results
+	s := random_test_set(4)
+	_, err := s.SolveAll(2, 0, func(problem LifeProblem, r *rand.Rand) *Board_BoolPacked {
+		if problem.id == 3 {
+			time.Sleep(30 * time.Millisecond)
+		}
+		return problem.end.Clone()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.results) != len(s.problem) {
+		t.Fatalf("SolveAll recorded %d results for %d problems", len(s.results), len(s.problem))
+	}
+	if slowest := SlowestProblems(s.results, 1); len(slowest) != 1 || slowest[0] != 3 {
+		t.Errorf("slowest problem from SolveAll is %v, want [3]", slowest)
+	}
+}
+
+func TestWriteRunReport(t *testing.T) {
+	ten_by_ten := &LifeProblemSet{problem: map[int]LifeProblem{}}
+	for id := 1; id <= 8; id++ {
+		ten_by_ten.problem[id] = LifeProblem{id: id, end: NewBoard_BoolPacked(10, 10)}
+	}
+	many := map[int]SolveResult{}
+	for id := 1; id <= 8; id++ {
+		many[id] = SolveResult{id: id, steps: 1, mismatch: id % 4, duration: time.Millisecond}
+	}
+
+	cases := []struct {
+		name     string
+		results  map[int]SolveResult
+		problems *LifeProblemSet
+		lines    []string // Each must appear, in this order
+		absent   []string
+	}{
+		{"small run",
+			map[int]SolveResult{
+				1: {id: 1, steps: 1, mismatch: 0, duration: time.Second},
+				2: {id: 2, steps: 1, mismatch: 10, duration: time.Second},
+				3: {id: 3, steps: 2, mismatch: 20, duration: time.Second},
+			}, ten_by_ten,
+			[]string{
+				"Problems       : 3",
+				"Mean mismatch  : 10.00",
+				"Exact matches  : 1 (33.3%)",
+				"Cell accuracy  : 0.9000",
+				"Total runtime  : 3s",
+				"  steps=1 :    2 problems, accuracy 0.9500",
+				"  steps=2 :    1 problems, accuracy 0.8000",
+				"  id[     3].steps=2 : mismatch=20, duration=1s",
+				"  id[     2].steps=1 : mismatch=10, duration=1s",
+				"  id[     1].steps=1 : mismatch=0, duration=1s",
+			}, nil},
+		{"ids without a problem count as full size boards",
+			map[int]SolveResult{9: {id: 9, steps: 3, mismatch: 40}}, ten_by_ten,
+			[]string{"Cell accuracy  : 0.9000", "  steps=3 :    1 problems, accuracy 0.9000"}, nil},
+		{"only the worst 5, ties in id order",
+			many, ten_by_ten,
+			[]string{"  id[     3]", "  id[     7]", "  id[     2]", "  id[     6]", "  id[     1]"},
+			[]string{"  id[     5]", "  id[     4]", "  id[     8]"}},
+		{"no results",
+			map[int]SolveResult{}, ten_by_ten,
+			[]string{"Problems       : 0", "Total runtime  : 0s"},
+			[]string{"Mean mismatch", "steps="}},
+	}
+	path := filepath.Join(t.TempDir(), "report.txt")
+	for _, c := range cases {
+		if err := WriteRunReport(path, c.results, c.problems); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		contents, _ := os.ReadFile(path)
+		report := string(contents)
+		at := 0
+		for _, line := range c.lines {
+			i := strings.Index(report[at:], line)
+			if i < 0 {
+				t.Errorf("%s: %q missing (or out of order) in\n%s", c.name, line, report)
+				break
+			}
+			at += i + len(line)
+		}
+		for _, line := range c.absent {
+			if strings.Contains(report, line) {
+				t.Errorf("%s: %q should not be in\n%s", c.name, line, report)
+			}
+		}
+	}
+
+	if err := WriteRunReport(filepath.Join(t.TempDir(), "absent", "report.txt"), many, ten_by_ten); err == nil {
+		t.Error("unwritable path : no error")
+	}
+}
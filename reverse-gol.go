@@ -1,12 +1,15 @@
 package main
 
-// GOPATH=`pwd` go build reverse-gol.go speed_packed.go ga.go board-standard.go transitions.go db.go && ./reverse-gol
+// GOPATH=`pwd` go build reverse-gol.go speed_packed.go ga.go board-standard.go transitions.go db.go constraints.go solvers.go scoring.go images.go submission.go speed_sparse.go rules.go logging.go snapshot.go cache.go && ./reverse-gol
 
 import (
 	"fmt"
 	"time"
 	"math/rand"
 	"flag"
+	"os"
+	"strconv"
+	"strings"
 )
 
 
@@ -74,7 +77,10 @@ func main_verify_training_examples(problem_offset int) {
 	for id := problem_offset; id < problem_offset+10; id++ {
 		id_list = append(id_list, id)
 	}
-	kaggle.load_csv(is_training, id_list)
+	if err := kaggle.LoadCSV(is_training, id_list, board_width, board_height); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 	//fmt.Println(kaggle.problem[107].start)
 	//fmt.Println(kaggle.problem[107].end)
 
@@ -146,7 +152,10 @@ func main_population_score(is_training bool, id int) {
 	image := NewImageSet(10, 12) // 10 rows of 12 images each, formatted 'appropriately'
 	
 	var kaggle LifeProblemSet
-	kaggle.load_csv(is_training, []int{id}) // Load from the CSV
+	if err := kaggle.LoadCSV(is_training, []int{id}, board_width, board_height); err != nil { // Load from the CSV
+		fmt.Println("Error:", err)
+		return
+	}
 
 	problem := kaggle.problem[id]
 	
@@ -334,6 +343,111 @@ func main_create_fake_training_data() {
 // 1020 - Fix mental problem of fake_data starting from same seed as synthetic_transition board generator...
 const currently_running_version int = 1020
 
+// Reads a comma-separated list of ids, e.g. "1,2,3"
+func parse_ids(list string) ([]int, error) {
+	ids := []int{}
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("bad id '%s' in -ids", field)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Flag combinations that -mode can't work with (these get the usage message)
+func check_mode_flags(mode, in, out string, ids []int) error {
+	switch mode {
+	case "train", "test", "render":
+	default:
+		return fmt.Errorf("Unknown '-mode=%s' : expected train, test or render", mode)
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("Need to specify '-ids=1,2,3' for -mode=%s", mode)
+	}
+	if mode == "render" && in == "" {
+		return fmt.Errorf("Need to specify '-in=<csv>' for -mode=render")
+	}
+	if mode == "test" && out == "" {
+		return fmt.Errorf("Need to specify '-out=<submission csv>' for -mode=test")
+	}
+	return nil
+}
+
+// The scriptable entry point :
+//   train  : solve the training problems in 'in' (default data/train.csv) and report the Kaggle-style score,
+//            plus write the predictions to 'out' as a submission, if given
+//   test   : solve the test problems in 'in' (default data/test.csv), writing the submission to 'out'
+//   render : draw the problems' start (training only) and end boards, one row per id, into the PNG 'out'
+func main_mode(mode, in, out string, ids []int, is_training bool, workers int, seed int64) error {
+	var kaggle LifeProblemSet
+	switch mode {
+	case "train":
+		is_training = true
+		if in == "" {
+			in = "data/train.csv"
+		}
+	case "test":
+		is_training = false
+		if in == "" {
+			in = "data/test.csv"
+		}
+	case "render":
+		if out == "" {
+			out = "images/render.png"
+		}
+	}
+	if err := kaggle.load_csv_from_file(in, is_training, true, ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, ok := kaggle.problem[id]; !ok {
+			return fmt.Errorf("id %d not found in %s", id, in)
+		}
+	}
+
+	if mode == "render" {
+		// Test problems have no start board, so they only get the end column
+		cols := 1
+		if is_training {
+			cols = 2
+		}
+		image := NewImageSet(len(ids), cols)
+		for _, id := range ids {
+			if is_training {
+				bs_start := NewBoardStats(board_width, board_height)
+				kaggle.problem[id].start.AddToStats(bs_start)
+				image.DrawStatsNext(bs_start)
+			}
+			bs_end := NewBoardStats(board_width, board_height)
+			kaggle.problem[id].end.AddToStats(bs_end)
+			image.DrawStatsNext(bs_end)
+		}
+		return image.save(out)
+	}
+
+	if mode == "train" {
+		kaggle.metric = &MetricAccumulator{}
+	}
+	predictions, err := kaggle.SolveAll(workers, seed, DeterministicSolver(func(problem LifeProblem) *Board_BoolPacked {
+		return problem.Solve(1)[0]
+	}))
+	if err != nil {
+		return err
+	}
+	if mode == "train" {
+		fmt.Printf("Kaggle-style score over %d problems : %8.6f\n", len(ids), kaggle.metric.Mean())
+	}
+	if out == "" {
+		return nil
+	}
+	return kaggle.WriteSubmission(out, predictions)
+}
+
 func main() {
 	cmd:= flag.String("cmd", "", "Required : {db|create|visualize|run|submit}")
 	cmd_type:= flag.String("type", "", "create:{fake_training_data|training_set_transitions|synthetic_transitions}, db:{test|insert_problems}, visualize:{data|ga}, submit:{kaggle|fakescore}")
@@ -346,6 +460,11 @@ func main() {
 
 	count := flag.Int("count", 0, "Number of ids to process")
 
+	mode    := flag.String("mode", "", "Scriptable alternative to -cmd (no db needed) : {train|test|render}")
+	in      := flag.String("in", "", "mode : CSV to load (train/test default to data/train.csv and data/test.csv)")
+	out     := flag.String("out", "", "mode : Submission CSV to write (train/test), or PNG (render, default images/render.png)")
+	ids     := flag.String("ids", "", "mode : Comma-separated problem ids, e.g. 1,2,3")
+	workers := flag.Int("workers", 0, "mode : Number of solver workers (0 = one per CPU)")
 	
 	flag.Parse()
 	//fmt.Printf("CMD = %s\n", *cmd)
@@ -353,6 +472,26 @@ func main() {
 	//rand.Seed(time.Now().UnixNano()) 
 	rand.Seed(*seed)
 	
+	/// ./reverse-gol -mode=train -ids=1,2,3 -workers=4
+	/// ./reverse-gol -mode=test -ids=1,2,3 -out=submissions/partial.csv
+	/// ./reverse-gol -mode=render -in=data/train.csv -training=true -ids=1,2,3 -out=images/render.png
+	if *mode!="" {
+		id_list, err := parse_ids(*ids)
+		if err == nil {
+			err = check_mode_flags(*mode, *in, *out, id_list)
+		}
+		if err != nil {
+			fmt.Println(err)
+			flag.Usage()
+			os.Exit(2)
+		}
+		if err := main_mode(*mode, *in, *out, id_list, *training_only, *workers, *seed); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	
 	//main_timer()
 	//main_visualize_density()
 	
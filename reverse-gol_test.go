@@ -0,0 +1,137 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseIds(t *testing.T) {
+	cases := []struct {
+		list    string
+		want    []int
+		wantErr bool
+	}{
+		{"", []int{}, false},
+		{"7", []int{7}, false},
+		{"1,2,3", []int{1, 2, 3}, false},
+		{" 4 , 5 ,", []int{4, 5}, false},
+		{"1,,2", []int{1, 2}, false},
+		{"1,two,3", nil, true},
+		{"1.5", nil, true},
+	}
+	for _, c := range cases {
+		got, err := parse_ids(c.list)
+		if (err != nil) != c.wantErr {
+			t.Errorf("parse_ids(%q): err = %v, wantErr %v", c.list, err, c.wantErr)
+			continue
+		}
+		if !c.wantErr && !reflect.DeepEqual(got, c.want) {
+			t.Errorf("parse_ids(%q) = %v, want %v", c.list, got, c.want)
+		}
+	}
+}
+
+func TestCheckModeFlags(t *testing.T) {
+	ids := []int{1, 2}
+	cases := []struct {
+		name, mode, in, out string
+		ids                 []int
+		wantErr             string // "" for a valid combination
+	}{
+		{"train with defaults", "train", "", "", ids, ""},
+		{"test with out", "test", "", "sub.csv", ids, ""},
+		{"render with in", "render", "train.csv", "", ids, ""},
+		{"unknown mode", "solve", "", "", ids, "Unknown"},
+		{"no ids", "train", "", "", []int{}, "-ids"},
+		{"render without in", "render", "", "out.png", ids, "-in"},
+		{"test without out", "test", "test.csv", "", ids, "-out"},
+	}
+	for _, c := range cases {
+		err := check_mode_flags(c.mode, c.in, c.out, c.ids)
+		switch {
+		case c.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", c.name, err)
+		case c.wantErr != "" && (err == nil || !strings.Contains(err.Error(), c.wantErr)):
+			t.Errorf("%s: got error %v, want one mentioning %q", c.name, err, c.wantErr)
+		}
+	}
+}
+
+// -mode=render draws a start column for training data only
+func TestMainModeRender(t *testing.T) {
+	dir := t.TempDir()
+	var training LifeProblemSet
+	training.problem = map[int]LifeProblem{}
+	header := []string{"id", "delta"}
+	for i := 1; i <= board_width*board_height; i++ {
+		header = append(header, fmt.Sprintf("stop.%d", i))
+	}
+	test_csv := strings.Join(header, ",") + "\n"
+	for id := 1; id <= 3; id++ {
+		p := problem_from_start(id, random_board(board_width, board_height, 0.3, int64(id)), 1)
+		training.problem[id] = p
+		cells := []string{}
+		for y := 0; y < board_height; y++ {
+			for x := 0; x < board_width; x++ {
+				cells = append(cells, map[bool]string{false: "0", true: "1"}[p.end.isSet(x, y)])
+			}
+		}
+		test_csv += fmt.Sprintf("%d,1,%s\n", id, strings.Join(cells, ","))
+	}
+	train_in, test_in := filepath.Join(dir, "train.csv"), filepath.Join(dir, "test.csv")
+	training.save_csv(train_in)
+	if err := os.WriteFile(test_in, []byte(test_csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name        string
+		in          string
+		is_training bool
+		cols        int
+	}{
+		{"training", train_in, true, 2},
+		{"test", test_in, false, 1},
+	}
+	for _, c := range cases {
+		out := filepath.Join(dir, c.name+".png")
+		if err := main_mode("render", c.in, out, []int{1, 2, 3}, c.is_training, 1, 1); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		f, err := os.Open(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		im, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got := im.Bounds().Dx(); got != c.cols*(board_width+2)+2 {
+			t.Errorf("%s: image is %d wide, want %d columns (%d)", c.name, got, c.cols, c.cols*(board_width+2)+2)
+		}
+	}
+
+	failures := []struct {
+		name string
+		ids  []int
+		out  string
+	}{
+		{"id not in the file", []int{99}, filepath.Join(dir, "missing.png")},
+		{"out directory doesn't exist", []int{1}, filepath.Join(dir, "no-such-dir", "render.png")},
+		{"out is a directory", []int{1}, dir},
+	}
+	for _, c := range failures {
+		if err := main_mode("render", train_in, c.out, c.ids, true, 1, 1); err == nil {
+			t.Errorf("%s: rendering should fail", c.name)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// A Rule says, by number of live neighbours, when a dead cell is born and when a live cell survives
+type Rule struct {
+	birth   [9]bool
+	survive [9]bool
+}
+
+// NewRule builds a Rule from the neighbour counts, e.g. NewRule([]int{3}, []int{2,3}) is B3/S23
+func NewRule(birth, survive []int) Rule {
+	rule := Rule{}
+	for _, n := range birth {
+		rule.birth[n] = true
+	}
+	for _, n := range survive {
+		rule.survive[n] = true
+	}
+	return rule
+}
+
+var RuleConway = NewRule([]int{3}, []int{2, 3})     // B3/S23
+var RuleHighLife = NewRule([]int{3, 6}, []int{2, 3}) // B36/S23
+
+// ParseRule reads the B/S notation, e.g. "B3/S23" (Conway) or "B36/S23" (HighLife).
+// Either part may come first, and may be empty ("B3/S" : nothing survives)
+func ParseRule(notation string) (Rule, error) {
+	parts := strings.Split(strings.ToUpper(strings.TrimSpace(notation)), "/")
+	if len(parts) != 2 {
+		return Rule{}, fmt.Errorf("bad rule '%s' : expected B.../S...", notation)
+	}
+	rule := Rule{}
+	seen := map[byte]bool{}
+	for _, part := range parts {
+		if part == "" || (part[0] != 'B' && part[0] != 'S') || seen[part[0]] {
+			return Rule{}, fmt.Errorf("bad rule '%s' : expected one B part and one S part", notation)
+		}
+		seen[part[0]] = true
+		counts := &rule.birth
+		if part[0] == 'S' {
+			counts = &rule.survive
+		}
+		for _, c := range part[1:] {
+			if c < '0' || c > '8' {
+				return Rule{}, fmt.Errorf("bad rule '%s' : '%c' is not a neighbour count 0-8", notation, c)
+			}
+			counts[c-'0'] = true
+		}
+	}
+	return rule, nil
+}
+
+// String gives the rule in B/S notation
+func (rule Rule) String() string {
+	var sb strings.Builder
+	sb.WriteString("B")
+	for n, born := range rule.birth {
+		if born {
+			sb.WriteString(fmt.Sprint(n))
+		}
+	}
+	sb.WriteString("/S")
+	for n, survives := range rule.survive {
+		if survives {
+			sb.WriteString(fmt.Sprint(n))
+		}
+	}
+	return sb.String()
+}
+
+// Next state of a cell, given its current state and number of live neighbours
+func (rule Rule) next(alive bool, neighbours int) bool {
+	if alive {
+		return rule.survive[neighbours]
+	}
+	return rule.birth[neighbours]
+}
+
+// RuleTransitionTable gives the next state of the centre cell for each of the 512 3x3 neighbourhood codes 
+// (bit layout as window_bit() : top row in bits 6..8, lowest bit = left-most column)
+func RuleTransitionTable(rule Rule) [512]bool {
+	var table [512]bool
+	for code := 0; code < 512; code++ {
+		neighbours := bits.OnesCount(uint(code &^ window_center))
+		table[code] = rule.next(code&window_center != 0, neighbours)
+	}
+	return table
+}
+
+// Same as Iterate(), but the rule comes from a RuleTransitionTable
+func (f *Board_BoolPacked) Iterate1LookupRule(next *Board_BoolPacked, table *[512]bool) { // OPTIMIZED FOR BoolPacked
+	next.wrap = f.wrap
+	if f.wrap { // As in Iterate(), the fast path relies on dead padding
+		for y := 0; y < f.h; y++ {
+			for x := 0; x < f.w; x++ {
+				code := 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if f.isSet_wrap(x+dx, y+dy) {
+							code |= 1 << window_bit(dx, dy)
+						}
+					}
+				}
+				next.Set(x, y, table[code])
+			}
+		}
+		return
+	}
+
+	next.s[0] = 0
+	for r := 1; r <= f.h; r++ {
+		r_top := f.s[r-1]
+		r_mid := f.s[r]
+		r_bot := f.s[r+1]
+
+		acc := int32(0)
+		p := int32(2) // Start one column in
+		for c := 1; c <= f.w; c++ {
+			if table[((r_top&7)<<6)|((r_mid&7)<<3)|(r_bot&7)] {
+				acc |= p
+			}
+			p <<= 1
+
+			// Shift the rows over, so the next column's window is in the lowest 3 bits
+			r_top >>= 1
+			r_mid >>= 1
+			r_bot >>= 1
+		}
+		next.s[r] = acc
+	}
+	next.s[f.h+1] = 0
+}
+
+// What lies beyond the edge of the board
+type BoundaryMode int
+
+const (
+	BoundaryDead BoundaryMode = iota // Everything outside is dead (the Kaggle setting)
+	BoundaryWrap                     // The board is a torus
+)
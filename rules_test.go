@@ -0,0 +1,156 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestRuleTransitionTable(t *testing.T) {
+	conway := RuleTransitionTable(RuleConway)
+	for code := 0; code < 512; code++ {
+		if conway[code] != conway_next(code) {
+			t.Fatalf("Conway table differs from conway_next at %09b", code)
+		}
+	}
+
+	cases := []struct {
+		name string
+		rule Rule
+		// Which entries should differ from Conway's, and how many of them there are
+		differs func(code int) bool
+		count   int
+	}{
+		{"Conway", RuleConway, func(code int) bool { return false }, 0},
+		{"HighLife births on six", RuleHighLife, func(code int) bool {
+			return code&window_center == 0 && bits.OnesCount(uint(code)) == 6
+		}, 28},
+		{"B3/S2 loses survival on three", NewRule([]int{3}, []int{2}), func(code int) bool {
+			return code&window_center != 0 && bits.OnesCount(uint(code&^window_center)) == 3
+		}, 56},
+	}
+	for _, c := range cases {
+		table := RuleTransitionTable(c.rule)
+		differing := 0
+		for code := 0; code < 512; code++ {
+			if (table[code] != conway[code]) != c.differs(code) {
+				t.Errorf("%s: entry %09b is %v", c.name, code, table[code])
+			}
+			if table[code] != conway[code] {
+				differing++
+			}
+		}
+		if differing != c.count {
+			t.Errorf("%s: %d entries differ from Conway, want %d", c.name, differing, c.count)
+		}
+	}
+}
+
+// Iterate1LookupRule agrees with the cell-by-cell rule
+func TestIterate1LookupRule(t *testing.T) {
+	cases := []struct {
+		name string
+		rule Rule
+	}{
+		{"Conway", RuleConway},
+		{"HighLife", RuleHighLife},
+		{"Seeds", NewRule([]int{2}, nil)},
+	}
+	for _, c := range cases {
+		table := RuleTransitionTable(c.rule)
+		for seed := int64(0); seed < 20; seed++ {
+			b := random_board(board_width, board_height, 0.45, seed)
+			got := NewBoard_BoolPacked(board_width, board_height)
+			b.Iterate1LookupRule(got, &table)
+			for y := 0; y < b.h; y++ {
+				for x := 0; x < b.w; x++ {
+					neighbours := 0
+					for dy := -1; dy <= 1; dy++ {
+						for dx := -1; dx <= 1; dx++ {
+							if (dx != 0 || dy != 0) && b.isSet_safe(x+dx, y+dy) {
+								neighbours++
+							}
+						}
+					}
+					if got.isSet(x, y) != c.rule.next(b.isSet(x, y), neighbours) {
+						t.Fatalf("%s: seed %d differs at (%d,%d)", c.name, seed, x, y)
+					}
+				}
+			}
+			if got.Validate() != nil {
+				t.Fatalf("%s: %v", c.name, got.Validate())
+			}
+		}
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	cases := []struct {
+		notation string
+		rule     Rule
+		str      string
+	}{
+		{"B3/S23", RuleConway, "B3/S23"},
+		{"b3/s23", RuleConway, "B3/S23"},
+		{" B3/S23 ", RuleConway, "B3/S23"},
+		{"S23/B3", RuleConway, "B3/S23"},
+		{"B36/S23", RuleHighLife, "B36/S23"},
+		{"s23/b63", RuleHighLife, "B36/S23"},
+		{"B3/S", NewRule([]int{3}, nil), "B3/S"},
+		{"B/S012345678", NewRule(nil, []int{0, 1, 2, 3, 4, 5, 6, 7, 8}), "B/S012345678"},
+	}
+	for _, c := range cases {
+		rule, err := ParseRule(c.notation)
+		if err != nil {
+			t.Errorf("%q: %v", c.notation, err)
+			continue
+		}
+		if rule != c.rule || rule.String() != c.str {
+			t.Errorf("%q: parsed as %s, want %s", c.notation, rule, c.str)
+		}
+	}
+
+	for _, bad := range []string{"", "B3", "B3/S23/S2", "B9/S23", "X3/S23", "B3/B3", "B3/Sx", "/", "B3S23"} {
+		if _, err := ParseRule(bad); err == nil {
+			t.Errorf("%q: no error", bad)
+		}
+	}
+}
+
+// A B3/S23 parsed from its notation, run through the rule-table paths, matches the hard-coded Conway iteration
+func TestParsedConwayMatchesIterate(t *testing.T) {
+	conway, err := ParseRule("B3/S23")
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := RuleTransitionTable(conway)
+	for seed := int64(0); seed < 40; seed++ {
+		b := random_board(board_width, board_height, 0.4, seed)
+		b.SetWrap(seed%2 == 1)
+
+		want := NewBoardIterator(b.w, b.h)
+		want.current.CopyFrom(b)
+		want.Iterate(5)
+
+		// NewBoardIteratorRule keeps Conway on the fast path, so force the table in
+		tabled := NewBoardIteratorRule(b.w, b.h, conway)
+		tabled.table = &table
+		tabled.current.CopyFrom(b)
+		tabled.Iterate(5)
+		if tabled.current.CompareTo(want.current, nil) != 0 {
+			t.Fatalf("seed %d: table iteration differs from Iterate", seed)
+		}
+		for y := 0; y < b.h; y++ {
+			for x := 0; x < b.w; x++ {
+				if b.IterateCellRule(x, y, conway) != b.IterateCell(x, y) {
+					t.Fatalf("seed %d: IterateCellRule(%d,%d) differs from IterateCell", seed, x, y)
+				}
+			}
+		}
+	}
+	if NewBoardIteratorRule(board_width, board_height, RuleHighLife).table == nil {
+		t.Error("a non-Conway rule did not get a transition table")
+	}
+}
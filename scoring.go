@@ -0,0 +1,223 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// ExactMatchCount counts the problems whose predicted start forward-iterates to exactly the end board.
+// Predictions for ids that aren't in the problem set are ignored
+func ExactMatchCount(problems *LifeProblemSet, predictions map[int]*Board_BoolPacked) int {
+	count := 0
+	for id, start := range predictions {
+		problem, ok := problems.problem[id]
+		if !ok {
+			continue
+		}
+		if matches, _ := problem.Verify(start); matches {
+			count++
+		}
+	}
+	return count
+}
+
+// Score compares a predicted start against the true one : The number of wrong cells, and the fraction right
+// (the Kaggle metric is the mean of 1-accuracy over the boards)
+func Score(predicted, truth *Board_BoolPacked) (wrongCells int, accuracy float32) {
+	wrongCells = predicted.HammingDistance(truth)
+	return wrongCells, 1 - float32(wrongCells)/float32(truth.w*truth.h)
+}
+
+// MeanError is the Kaggle metric (mean per-cell error) for the predictions over every problem in the set 
+// that has a known start.  A missing prediction is scored as a blank board
+func (s *LifeProblemSet) MeanError(predictions map[int]*Board_BoolPacked) float32 {
+	m := MetricAccumulator{}
+	for id, problem := range s.problem {
+		if problem.start == nil {
+			continue
+		}
+		predicted := predictions[id]
+		if predicted == nil {
+			predicted = NewBoard_BoolPacked(problem.start.w, problem.start.h)
+		}
+		m.Add(problem.start, predicted)
+	}
+	return float32(m.Mean())
+}
+
+// MetricAccumulator keeps a running Kaggle score (mean per-cell error) as problems complete.
+// Add is safe to call from several workers at once
+type MetricAccumulator struct {
+	mutex        sync.Mutex
+	wrong, cells int
+	boards       int
+}
+
+// Add scores one prediction.  Boards may differ in size from problem to problem, but truth and predicted must match
+// (it panics otherwise), and nothing is added
+func (m *MetricAccumulator) Add(truth, predicted *Board_BoolPacked) {
+	if truth.w != predicted.w || truth.h != predicted.h {
+		panic(fmt.Sprintf("board dimensions differ : %dx%d vs %dx%d", truth.w, truth.h, predicted.w, predicted.h))
+	}
+	wrong := truth.CompareTo(predicted, nil)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.wrong += wrong
+	m.cells += truth.w * truth.h
+	m.boards++
+}
+
+// Mean is the per-cell error over everything added so far (0 if nothing has been added)
+func (m *MetricAccumulator) Mean() float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.cells == 0 {
+		return 0
+	}
+	return float64(m.wrong) / float64(m.cells)
+}
+
+// MismatchOverSteps follows both trajectories forward, returning the number of differing cells
+// at each step 0..steps.  Shows whether small errors in a predicted start amplify or die away
+func MismatchOverSteps(predictedStart, trueStart *Board_BoolPacked, steps int) []int {
+	predicted := NewBoardIterator(predictedStart.w, predictedStart.h)
+	predicted.current.CopyFrom(predictedStart)
+	truth := NewBoardIterator(trueStart.w, trueStart.h)
+	truth.current.CopyFrom(trueStart)
+
+	mismatch := make([]int, steps+1)
+	for i := 0; i <= steps; i++ {
+		if i > 0 {
+			predicted.Iterate(1)
+			truth.Iterate(1)
+		}
+		mismatch[i] = predicted.current.CompareTo(truth.current, nil)
+	}
+	return mismatch
+}
+
+// WinRate compares two sets of predictions problem-by-problem against the true starts (training data only).
+// Only ids predicted by both a and b are counted, and (as in MeanError) problems without a known start are skipped
+func WinRate(problems *LifeProblemSet, a, b map[int]*Board_BoolPacked) (aWins, bWins, ties int) {
+	for id, problem := range problems.problem {
+		start_a, start_b := a[id], b[id]
+		if start_a == nil || start_b == nil || problem.start == nil {
+			continue
+		}
+		mismatch_a := start_a.CompareTo(problem.start, nil)
+		mismatch_b := start_b.CompareTo(problem.start, nil)
+		switch {
+		case mismatch_a < mismatch_b:
+			aWins++
+		case mismatch_b < mismatch_a:
+			bWins++
+		default:
+			ties++
+		}
+	}
+	return aWins, bWins, ties
+}
+
+// BatchHamming sums the cell mismatches between truth and predicted over a pool of workers.
+// Both maps must cover exactly the same ids
+func BatchHamming(truth, predicted map[int]*Board_BoolPacked, workers int) (total int, err error) {
+	if len(truth) != len(predicted) {
+		return 0, fmt.Errorf("id sets differ : %d truths vs %d predictions", len(truth), len(predicted))
+	}
+	for id := range truth {
+		if _, ok := predicted[id]; !ok {
+			return 0, fmt.Errorf("id sets differ : no prediction for id %d", id)
+		}
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	queue := make(chan int)
+	var sum int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				atomic.AddInt64(&sum, int64(predicted[id].CompareTo(truth[id], nil)))
+			}
+		}()
+	}
+	for id := range truth {
+		queue <- id
+	}
+	close(queue)
+	wg.Wait()
+
+	return int(sum), nil
+}
+
+type threshold_cell struct {
+	p     float64
+	alive bool
+}
+
+func threshold_cells(cells []threshold_cell, prob [][]float64, truth *Board_BoolPacked) []threshold_cell {
+	for y := 0; y < truth.h; y++ {
+		for x := 0; x < truth.w; x++ {
+			cells = append(cells, threshold_cell{prob[y][x], truth.isSet(x, y)})
+		}
+	}
+	return cells
+}
+
+// Every distinct probability is tried as a threshold, as is +Inf (everything dead).  Ties go to the lowest threshold
+func best_threshold(cells []threshold_cell) (threshold float64, mismatch int) {
+	sort.Slice(cells, func(i, j int) bool { return cells[i].p < cells[j].p })
+	live := 0
+	for _, c := range cells {
+		if c.alive {
+			live++
+		}
+	}
+
+	// Sweep t up through the sorted probabilities : Cells below t are predicted dead, the rest alive.
+	// At the lowest t everything is alive, so only the dead cells are wrong
+	threshold, mismatch = math.Inf(1), live
+	wrong := len(cells) - live
+	for i := 0; i < len(cells); i++ {
+		if i == 0 || cells[i].p != cells[i-1].p {
+			if wrong < mismatch || wrong == mismatch && cells[i].p < threshold {
+				threshold, mismatch = cells[i].p, wrong
+			}
+		}
+		if cells[i].alive { // This cell drops to 'dead' for all higher thresholds
+			wrong++
+		} else {
+			wrong--
+		}
+	}
+	return threshold, mismatch
+}
+
+// OptimalThreshold finds the t for which 'alive where prob >= t' (prob indexed [y][x]) best matches truth
+func OptimalThreshold(prob [][]float64, truth *Board_BoolPacked) (threshold float64, mismatch int) {
+	return best_threshold(threshold_cells(nil, prob, truth))
+}
+
+// CalibrateThreshold finds the single threshold (as for OptimalThreshold) with the least total mismatch 
+// over a validation set.  Only ids with both a probability board and a truth are used
+func CalibrateThreshold(probs map[int][][]float64, truths map[int]*Board_BoolPacked) float64 {
+	cells := []threshold_cell{}
+	for id, prob := range probs {
+		if truth, ok := truths[id]; ok {
+			cells = threshold_cells(cells, prob, truth)
+		}
+	}
+	threshold, _ := best_threshold(cells)
+	return threshold
+}
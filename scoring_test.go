@@ -0,0 +1,444 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestExactMatchCount(t *testing.T) {
+	glider := problem_from_start(1, board_from_rows(board_width, board_height, "-X", "--X", "XXX"), 2)
+	block := problem_from_start(2, board_from_rows(board_width, board_height, "", "-XX", "-XX"), 3)
+	dies := problem_from_start(3, board_from_rows(board_width, board_height, "", "", "---X"), 1)
+	problems := &LifeProblemSet{problem: map[int]LifeProblem{1: glider, 2: block, 3: dies}}
+
+	approximate := NewBoard_BoolPacked(board_width, board_height)
+	approximate.CopyFrom(glider.start)
+	approximate.Set(7, 7, true)
+
+	cases := []struct {
+		name        string
+		predictions map[int]*Board_BoolPacked
+		want        int
+	}{
+		{"one exact, one approximate", map[int]*Board_BoolPacked{1: glider.start, 2: approximate}, 1},
+		{"all exact", map[int]*Board_BoolPacked{1: glider.start, 2: block.start, 3: dies.start}, 3},
+		{"a different start that still reaches the end", map[int]*Board_BoolPacked{3: NewBoard_BoolPacked(board_width, board_height)}, 1},
+		{"unknown ids are ignored", map[int]*Board_BoolPacked{1: glider.start, 99: glider.start}, 1},
+		{"nothing predicted", map[int]*Board_BoolPacked{}, 0},
+	}
+	for _, c := range cases {
+		if got := ExactMatchCount(problems, c.predictions); got != c.want {
+			t.Errorf("%s: ExactMatchCount() = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestMetricAccumulatorConcurrent(t *testing.T) {
+	cases := []struct {
+		name  string
+		pairs int
+	}{
+		{"nothing added", 0},
+		{"one pair", 1},
+		{"many pairs", 200},
+	}
+	for _, c := range cases {
+		truths, predictions := make([]*Board_BoolPacked, c.pairs), make([]*Board_BoolPacked, c.pairs)
+		wrong := 0
+		for i := range truths {
+			truths[i] = random_board(board_width, board_height, 0.3, int64(i))
+			predictions[i] = random_board(board_width, board_height, 0.3, int64(i+1000))
+			wrong += truths[i].CompareTo(predictions[i], nil)
+		}
+		want := 0.0
+		if c.pairs > 0 {
+			want = float64(wrong) / float64(c.pairs*board_width*board_height)
+		}
+
+		var m MetricAccumulator
+		var wg sync.WaitGroup
+		for i := range truths {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				m.Add(truths[i], predictions[i])
+				m.Mean()
+			}(i)
+		}
+		wg.Wait()
+		if got := m.Mean(); got != want {
+			t.Errorf("%s: Mean() = %v, want the batch mean %v", c.name, got, want)
+		}
+	}
+
+	// Boards of different sizes (per problem) are fine, but a truth and prediction that differ in size are rejected
+	var m MetricAccumulator
+	m.Add(board_from_rows(4, 4, "XX"), board_from_rows(4, 4, "X"))
+	m.Add(board_from_rows(8, 2, "X"), board_from_rows(8, 2, "-X", "-X"))
+	if got, want := m.Mean(), 4.0/32; got != want {
+		t.Errorf("mixed sizes : Mean() = %v, want %v", got, want)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Add of a 4x4 truth and 5x4 prediction didn't panic")
+			}
+		}()
+		m.Add(board_from_rows(4, 4, "XX"), board_from_rows(5, 4, "XX"))
+	}()
+	if got, want := m.Mean(), 4.0/32; got != want {
+		t.Errorf("after the rejected Add : Mean() = %v, want %v", got, want)
+	}
+}
+
+// SolveAll scores each training prediction into the set's metric as it completes
+func TestSolveAllMetric(t *testing.T) {
+	s := &LifeProblemSet{problem: map[int]LifeProblem{}, is_training: true, metric: &MetricAccumulator{}}
+	for id := 1; id <= 20; id++ {
+		s.problem[id] = problem_from_start(id, random_board(board_width, board_height, 0.3, int64(id)), 1)
+	}
+	predictions, err := s.SolveAll(8, 1, random_solver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var batch MetricAccumulator
+	for id, p := range s.problem {
+		batch.Add(p.start, predictions[id])
+	}
+	if s.metric.Mean() != batch.Mean() {
+		t.Errorf("live metric %v, batch metric %v", s.metric.Mean(), batch.Mean())
+	}
+}
+
+func TestMismatchOverSteps(t *testing.T) {
+	r_pentomino := board_from_rows(20, 20, "", "", "", "", "", "", "", "--------XX", "-------XX", "--------X")
+	block := board_from_rows(10, 10, "", "-XX", "-XX")
+	with := func(b *Board_BoolPacked, x, y int, alive bool) *Board_BoolPacked {
+		c := NewBoard_BoolPacked(b.w, b.h)
+		c.CopyFrom(b)
+		c.Set(x, y, alive)
+		return c
+	}
+	cases := []struct {
+		name                  string
+		predicted, true_start *Board_BoolPacked
+		steps                 int
+		want                  []int
+	}{
+		{"identical", block, block, 3, []int{0, 0, 0, 0}},
+		{"missing cell of an R-pentomino grows", with(r_pentomino, 9, 7, false), r_pentomino, 8, []int{1, 3, 5, 7, 8, 7, 8, 13, 18}},
+		{"stray isolated cell dies away", with(block, 6, 6, true), block, 4, []int{1, 0, 0, 0, 0}},
+		{"cell touching the block spreads", with(block, 3, 1, true), block, 4, []int{1, 5, 5, 4, 4}},
+		{"zero steps", with(block, 6, 6, true), block, 0, []int{1}},
+	}
+	for _, c := range cases {
+		got := MismatchOverSteps(c.predicted, c.true_start, c.steps)
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("%s: MismatchOverSteps() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestWinRate(t *testing.T) {
+	problems := &LifeProblemSet{problem: map[int]LifeProblem{}}
+	for id := 1; id <= 3; id++ {
+		problems.problem[id] = problem_from_start(id, random_board(board_width, board_height, 0.3, int64(id)), 1)
+	}
+	problems.problem[4] = LifeProblem{id: 4, steps: 1, end: problems.problem[1].end} // Test data : no start
+	// off_by returns problem id's true start with the first n cells flipped
+	off_by := func(id, n int) *Board_BoolPacked {
+		start := problems.problem[id].start
+		b := NewBoard_BoolPacked(start.w, start.h)
+		b.CopyFrom(start)
+		for i := 0; i < n; i++ {
+			b.Set(i, 0, !b.isSet(i, 0))
+		}
+		return b
+	}
+	cases := []struct {
+		name               string
+		a, b               map[int]*Board_BoolPacked
+		aWins, bWins, ties int
+	}{
+		{"one each and a tie", map[int]*Board_BoolPacked{1: off_by(1, 1), 2: off_by(2, 5), 3: off_by(3, 2)},
+			map[int]*Board_BoolPacked{1: off_by(1, 3), 2: off_by(2, 0), 3: off_by(3, 2)}, 1, 1, 1},
+		{"a sweeps", map[int]*Board_BoolPacked{1: off_by(1, 0), 2: off_by(2, 0), 3: off_by(3, 0)},
+			map[int]*Board_BoolPacked{1: off_by(1, 1), 2: off_by(2, 1), 3: off_by(3, 1)}, 3, 0, 0},
+		{"only ids predicted by both count", map[int]*Board_BoolPacked{1: off_by(1, 4), 2: off_by(2, 0)},
+			map[int]*Board_BoolPacked{1: off_by(1, 2), 3: off_by(3, 0)}, 0, 1, 0},
+		{"problems without a start are skipped", map[int]*Board_BoolPacked{1: off_by(1, 0), 4: off_by(1, 0)},
+			map[int]*Board_BoolPacked{1: off_by(1, 1), 4: off_by(1, 2)}, 1, 0, 0},
+	}
+	for _, c := range cases {
+		aWins, bWins, ties := WinRate(problems, c.a, c.b)
+		if aWins != c.aWins || bWins != c.bWins || ties != c.ties {
+			t.Errorf("%s: WinRate() = %d/%d/%d, want %d/%d/%d", c.name, aWins, bWins, ties, c.aWins, c.bWins, c.ties)
+		}
+	}
+}
+
+// A truth/prediction pair of maps over ids 1..count
+func random_prediction_maps(count int) (truth, predicted map[int]*Board_BoolPacked) {
+	truth, predicted = map[int]*Board_BoolPacked{}, map[int]*Board_BoolPacked{}
+	for id := 1; id <= count; id++ {
+		truth[id] = random_board(board_width, board_height, 0.3, int64(id))
+		predicted[id] = random_board(board_width, board_height, 0.3, int64(-id))
+	}
+	return truth, predicted
+}
+
+func TestBatchHamming(t *testing.T) {
+	truth, predicted := random_prediction_maps(500)
+	serial := 0
+	for id := range truth {
+		serial += truth[id].CompareTo(predicted[id], nil)
+	}
+	missing, _ := random_prediction_maps(500)
+	delete(missing, 7)
+	renumbered, _ := random_prediction_maps(499)
+	renumbered[1000] = truth[1]
+
+	cases := []struct {
+		name      string
+		predicted map[int]*Board_BoolPacked
+		workers   int
+		want      int
+		wantErr   bool
+	}{
+		{"one worker", predicted, 1, serial, false},
+		{"eight workers", predicted, 8, serial, false},
+		{"zero workers means one", predicted, 0, serial, false},
+		{"identical", truth, 4, 0, false},
+		{"missing id", missing, 4, 0, true},
+		{"different ids", renumbered, 4, 0, true},
+	}
+	for _, c := range cases {
+		got, err := BatchHamming(truth, c.predicted, c.workers)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: BatchHamming() error = %v", c.name, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%s: BatchHamming() = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func BenchmarkBatchHamming(b *testing.B) {
+	truth, predicted := random_prediction_maps(10000)
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				BatchHamming(truth, predicted, workers)
+			}
+		})
+	}
+}
+
+// prob_board gives every cell of truth a probability from the one of alive/dead that matches it
+func prob_board(truth *Board_BoolPacked, alive, dead func(r *rand.Rand) float64, seed int64) [][]float64 {
+	r := rand.New(rand.NewSource(seed))
+	prob := make([][]float64, truth.h)
+	for y := range prob {
+		prob[y] = make([]float64, truth.w)
+		for x := range prob[y] {
+			if truth.isSet(x, y) {
+				prob[y][x] = alive(r)
+			} else {
+				prob[y][x] = dead(r)
+			}
+		}
+	}
+	return prob
+}
+
+// The mismatch of predicting 'alive where prob >= threshold'
+func threshold_mismatch(prob [][]float64, truth *Board_BoolPacked, threshold float64) int {
+	wrong := 0
+	for y := 0; y < truth.h; y++ {
+		for x := 0; x < truth.w; x++ {
+			if (prob[y][x] >= threshold) != truth.isSet(x, y) {
+				wrong++
+			}
+		}
+	}
+	return wrong
+}
+
+func TestOptimalThreshold(t *testing.T) {
+	truth := random_board(board_width, board_height, 0.4, 1)
+	high := func(r *rand.Rand) float64 { return 0.3 + 0.7*r.Float64() }
+	low := func(r *rand.Rand) float64 { return 0.3 * r.Float64() }
+	noise := func(r *rand.Rand) float64 { return r.Float64() }
+	fixed := func(p float64) func(r *rand.Rand) float64 { return func(r *rand.Rand) float64 { return p } }
+
+	cases := []struct {
+		name      string
+		truth     *Board_BoolPacked
+		prob      [][]float64
+		threshold float64 // math.NaN() when only the mismatch is known, from trying every threshold
+		mismatch  int
+	}{
+		// Live cells all at 0.7, dead at 0.2 : anything in (0.2, 0.7] separates them, and ties go to the lowest tried
+		{"two levels", truth, prob_board(truth, fixed(0.7), fixed(0.2), 0), 0.7, 0},
+		{"all dead", NewBoard_BoolPacked(board_width, board_height), prob_board(truth, noise, noise, 2), math.Inf(1), 0},
+		{"all alive", random_board(board_width, board_height, 1, 0), prob_board(random_board(board_width, board_height, 1, 0), fixed(0.4), fixed(0.4), 0), 0.4, 0},
+		{"separable", truth, prob_board(truth, high, low, 3), math.NaN(), 0},
+		{"noise", truth, prob_board(truth, noise, noise, 4), math.NaN(), -1},
+		{"inverted", truth, prob_board(truth, low, high, 5), math.NaN(), -1},
+	}
+	for _, c := range cases {
+		threshold, mismatch := OptimalThreshold(c.prob, c.truth)
+		if !math.IsNaN(c.threshold) && threshold != c.threshold {
+			t.Errorf("%s: threshold %v, want %v", c.name, threshold, c.threshold)
+		}
+		if c.mismatch >= 0 && mismatch != c.mismatch {
+			t.Errorf("%s: mismatch %d, want %d", c.name, mismatch, c.mismatch)
+		}
+		if got := threshold_mismatch(c.prob, c.truth, threshold); got != mismatch {
+			t.Errorf("%s: threshold %v actually gives mismatch %d, not the %d reported", c.name, threshold, got, mismatch)
+		}
+
+		// No cell's probability (nor +Inf) does any better
+		best := threshold_mismatch(c.prob, c.truth, math.Inf(1))
+		for _, row := range c.prob {
+			for _, p := range row {
+				if wrong := threshold_mismatch(c.prob, c.truth, p); wrong < best {
+					best = wrong
+				}
+			}
+		}
+		if mismatch != best {
+			t.Errorf("%s: mismatch %d, but a threshold giving %d exists", c.name, mismatch, best)
+		}
+	}
+}
+
+func TestCalibrateThreshold(t *testing.T) {
+	fixed := func(p float64) func(r *rand.Rand) float64 { return func(r *rand.Rand) float64 { return p } }
+	noise := func(r *rand.Rand) float64 { return r.Float64() }
+	a, b := random_board(board_width, board_height, 0.4, 1), random_board(board_width, board_height, 0.4, 2)
+
+	cases := []struct {
+		name      string
+		probs     map[int][][]float64
+		truths    map[int]*Board_BoolPacked
+		threshold float64 // math.NaN() when only checked against trying every threshold
+	}{
+		// Alone, a's best threshold is 0.7 : but only (0.3, 0.6] separates both, and 0.6 is the lowest tried
+		{"shared separating threshold",
+			map[int][][]float64{1: prob_board(a, fixed(0.7), fixed(0.2), 0), 2: prob_board(b, fixed(0.6), fixed(0.3), 0)},
+			map[int]*Board_BoolPacked{1: a, 2: b}, 0.6},
+		{"probabilities without a truth are ignored",
+			map[int][][]float64{1: prob_board(a, fixed(0.7), fixed(0.2), 0), 3: prob_board(b, fixed(0.1), fixed(0.9), 0)},
+			map[int]*Board_BoolPacked{1: a, 2: b}, 0.7},
+		{"all dead", map[int][][]float64{1: prob_board(a, noise, noise, 1)},
+			map[int]*Board_BoolPacked{1: NewBoard_BoolPacked(board_width, board_height)}, math.Inf(1)},
+		{"conflicting",
+			map[int][][]float64{1: prob_board(a, fixed(0.5), fixed(0.2), 0), 2: prob_board(b, fixed(0.8), fixed(0.6), 0)},
+			map[int]*Board_BoolPacked{1: a, 2: b}, math.NaN()},
+		{"noise",
+			map[int][][]float64{1: prob_board(a, noise, noise, 1), 2: prob_board(b, noise, noise, 2)},
+			map[int]*Board_BoolPacked{1: a, 2: b}, math.NaN()},
+	}
+	for _, c := range cases {
+		total := func(threshold float64) int {
+			wrong := 0
+			for id, prob := range c.probs {
+				if truth, ok := c.truths[id]; ok {
+					wrong += threshold_mismatch(prob, truth, threshold)
+				}
+			}
+			return wrong
+		}
+
+		threshold := CalibrateThreshold(c.probs, c.truths)
+		if !math.IsNaN(c.threshold) && threshold != c.threshold {
+			t.Errorf("%s: threshold %v, want %v", c.name, threshold, c.threshold)
+		}
+		best := total(math.Inf(1))
+		for _, prob := range c.probs {
+			for _, row := range prob {
+				for _, p := range row {
+					if wrong := total(p); wrong < best {
+						best = wrong
+					}
+				}
+			}
+		}
+		if got := total(threshold); got != best {
+			t.Errorf("%s: threshold %v gives total mismatch %d, but %d is possible", c.name, threshold, got, best)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	truth := random_board(board_width, board_height, 0.3, 9)
+	one_flipped := truth.Clone()
+	one_flipped.Set(4, 7, !truth.isSet(4, 7))
+	inverted := truth.Clone()
+	for y := 0; y < truth.h; y++ {
+		for x := 0; x < truth.w; x++ {
+			inverted.Set(x, y, !truth.isSet(x, y))
+		}
+	}
+	cases := []struct {
+		name      string
+		predicted *Board_BoolPacked
+		wrong     int
+		accuracy  float32
+	}{
+		{"exact", truth.Clone(), 0, 1},
+		{"one flipped cell", one_flipped, 1, 1 - 1.0/400},
+		{"blank", NewBoard_BoolPacked(board_width, board_height), truth.Population(), 1 - float32(truth.Population())/400},
+		{"inverted", inverted, 400, 0},
+	}
+	for _, c := range cases {
+		wrong, accuracy := Score(c.predicted, truth)
+		if wrong != c.wrong || accuracy != c.accuracy {
+			t.Errorf("%s: Score = (%d, %g), want (%d, %g)", c.name, wrong, accuracy, c.wrong, c.accuracy)
+		}
+	}
+}
+
+func TestMeanError(t *testing.T) {
+	starts := map[int]*Board_BoolPacked{}
+	problems := &LifeProblemSet{problem: map[int]LifeProblem{}}
+	for id := 1; id <= 4; id++ {
+		starts[id] = random_board(board_width, board_height, 0.3, int64(id))
+		problems.problem[id] = problem_from_start(id, starts[id], 1)
+	}
+	// Test problems (no start) are left out of the mean
+	problems.problem[5] = LifeProblem{id: 5, steps: 1, end: starts[1]}
+
+	flipped := func(b *Board_BoolPacked, n int) *Board_BoolPacked {
+		f := b.Clone()
+		for i := 0; i < n; i++ {
+			f.Set(i, 0, !b.isSet(i, 0))
+		}
+		return f
+	}
+	cases := []struct {
+		name        string
+		predictions map[int]*Board_BoolPacked
+		want        float32
+	}{
+		{"all exact", map[int]*Board_BoolPacked{1: starts[1], 2: starts[2], 3: starts[3], 4: starts[4]}, 0},
+		{"one flipped cell", map[int]*Board_BoolPacked{1: flipped(starts[1], 1), 2: starts[2], 3: starts[3], 4: starts[4]}, 1.0 / 1600},
+		{"cells flipped in two problems", map[int]*Board_BoolPacked{1: flipped(starts[1], 4), 2: starts[2], 3: flipped(starts[3], 8), 4: starts[4]}, 12.0 / 1600},
+		{"missing scored as blank", map[int]*Board_BoolPacked{1: starts[1], 2: starts[2], 3: starts[3]}, float32(starts[4].Population()) / 1600},
+	}
+	for _, c := range cases {
+		if got := problems.MeanError(c.predictions); math.Abs(float64(got-c.want)) > 1e-7 {
+			t.Errorf("%s: MeanError = %g, want %g", c.name, got, c.want)
+		}
+	}
+}
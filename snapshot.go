@@ -0,0 +1,179 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Snapshot files are little-endian : int32 width, int32 height, then the h packed rows (as in Board_BoolPacked.s)
+func write_snapshot(w io.Writer, b *Board_BoolPacked) error {
+	if err := binary.Write(w, binary.LittleEndian, [2]int32{int32(b.w), int32(b.h)}); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, b.s[1:b.h+1])
+}
+
+// LoadSnapshot reads back a board written by SimulateToDisk
+func LoadSnapshot(path string) (*Board_BoolPacked, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var dims [2]int32
+	if err := binary.Read(file, binary.LittleEndian, &dims); err != nil {
+		return nil, fmt.Errorf("%s : reading dimensions : %v", path, err)
+	}
+	if dims[0] < 1 || dims[0] > 30 || dims[1] < 1 {
+		return nil, fmt.Errorf("%s : bad dimensions %dx%d", path, dims[0], dims[1])
+	}
+	b := NewBoard_BoolPacked(int(dims[0]), int(dims[1]))
+	if err := binary.Read(file, binary.LittleEndian, b.s[1:b.h+1]); err != nil {
+		return nil, fmt.Errorf("%s : reading rows : %v", path, err)
+	}
+	if err := b.Validate(); err != nil {
+		return nil, fmt.Errorf("%s : %v", path, err)
+	}
+	return b, nil
+}
+
+// Name of the snapshot file for generation gen
+func SnapshotPath(dir string, gen int) string {
+	return filepath.Join(dir, fmt.Sprintf("gen-%06d.bin", gen))
+}
+
+// SimulateToDisk iterates start for steps generations, writing a snapshot (see SnapshotPath) after 
+// every snapshotEvery of them, so a long run can be resumed from the latest one with LoadSnapshot
+func SimulateToDisk(start *Board_BoolPacked, steps, snapshotEvery int, dir string) error {
+	if snapshotEvery < 1 {
+		return fmt.Errorf("snapshotEvery must be positive, not %d", snapshotEvery)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	l := NewBoardIterator(start.w, start.h)
+	l.current.CopyFrom(start)
+	for gen := 1; gen <= steps; gen++ {
+		l.Iterate(1)
+		if gen%snapshotEvery != 0 {
+			continue
+		}
+
+		file, err := os.Create(SnapshotPath(dir, gen))
+		if err != nil {
+			return err
+		}
+		err = write_snapshot(file, l.current)
+		if close_err := file.Close(); err == nil {
+			err = close_err
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResumeSimulation picks up the highest-generation snapshot in dir, and iterates it additionalSteps further
+func ResumeSimulation(dir string, additionalSteps int) (*Board_BoolPacked, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "gen-*.bin"))
+	if err != nil {
+		return nil, err
+	}
+	latest, latest_gen := "", -1
+	for _, path := range paths {
+		gen := 0
+		name := strings.TrimSuffix(filepath.Base(path), ".bin")
+		if _, err := fmt.Sscanf(name, "gen-%d", &gen); err == nil && gen > latest_gen {
+			latest, latest_gen = path, gen
+		}
+	}
+	if latest_gen < 0 {
+		return nil, fmt.Errorf("%s : no snapshots found", dir)
+	}
+
+	b, err := LoadSnapshot(latest)
+	if err != nil {
+		return nil, err
+	}
+	l := NewBoardIterator(b.w, b.h)
+	l.current.CopyFrom(b)
+	l.Iterate(additionalSteps)
+	b.CopyFrom(l.current)
+	return b, nil
+}
+
+// In JSON a board is its dimensions plus the h packed rows, encoded as in a snapshot file (then base64)
+type board_json struct {
+	W    int    `json:"w"`
+	H    int    `json:"h"`
+	Wrap bool   `json:"wrap,omitempty"`
+	Rows string `json:"rows"`
+}
+
+func (f *Board_BoolPacked) MarshalJSON() ([]byte, error) {
+	var rows bytes.Buffer
+	if err := binary.Write(&rows, binary.LittleEndian, f.s[1:f.h+1]); err != nil {
+		return nil, err
+	}
+	return json.Marshal(board_json{W: f.w, H: f.h, Wrap: f.wrap, Rows: base64.StdEncoding.EncodeToString(rows.Bytes())})
+}
+
+func (f *Board_BoolPacked) UnmarshalJSON(data []byte) error {
+	var j board_json
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if j.W < 1 || j.W > 30 || j.H < 1 {
+		return fmt.Errorf("bad board dimensions %dx%d", j.W, j.H)
+	}
+	rows, err := base64.StdEncoding.DecodeString(j.Rows)
+	if err != nil {
+		return fmt.Errorf("board rows : %v", err)
+	}
+	if len(rows) != 4*j.H {
+		return fmt.Errorf("board rows are %d bytes, expected %d for %d rows", len(rows), 4*j.H, j.H)
+	}
+	b := NewBoard_BoolPacked(j.W, j.H)
+	if err := binary.Read(bytes.NewReader(rows), binary.LittleEndian, b.s[1:b.h+1]); err != nil {
+		return err
+	}
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	b.wrap = j.Wrap
+	*f = *b
+	return nil
+}
+
+type problem_json struct {
+	Id    int               `json:"id"`
+	Steps int               `json:"steps"`
+	Start *Board_BoolPacked `json:"start"` // null for test problems
+	End   *Board_BoolPacked `json:"end"`
+}
+
+func (problem LifeProblem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(problem_json{Id: problem.id, Steps: problem.steps, Start: problem.start, End: problem.end})
+}
+
+func (problem *LifeProblem) UnmarshalJSON(data []byte) error {
+	var j problem_json
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*problem = LifeProblem{id: j.Id, steps: j.Steps, start: j.Start, end: j.End}
+	return nil
+}
@@ -0,0 +1,188 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSimulateToDisk(t *testing.T) {
+	start := random_board(board_width, board_height, 0.4, 1)
+	cases := []struct {
+		name                 string
+		steps, snapshotEvery int
+		generations          []int
+		wantErr              bool
+	}{
+		{"10 steps every 5", 10, 5, []int{5, 10}, false},
+		{"7 steps every 3", 7, 3, []int{3, 6}, false},
+		{"every step", 3, 1, []int{1, 2, 3}, false},
+		{"fewer steps than the interval", 4, 5, nil, false},
+		{"bad interval", 10, 0, nil, true},
+	}
+	for _, c := range cases {
+		dir := filepath.Join(t.TempDir(), "snapshots")
+		err := SimulateToDisk(start, c.steps, c.snapshotEvery, dir)
+		if (err != nil) != c.wantErr {
+			t.Fatalf("%s: SimulateToDisk() error = %v", c.name, err)
+		}
+		if c.wantErr {
+			continue
+		}
+		files, _ := filepath.Glob(filepath.Join(dir, "*"))
+		if len(files) != len(c.generations) {
+			t.Errorf("%s: %d snapshot files, want %d", c.name, len(files), len(c.generations))
+		}
+		for _, gen := range c.generations {
+			b, err := LoadSnapshot(SnapshotPath(dir, gen))
+			if err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			}
+			if b.CompareTo(forward(start, gen), nil) != 0 {
+				t.Errorf("%s: snapshot %d isn't generation %d", c.name, gen, gen)
+			}
+		}
+	}
+}
+
+func TestLoadSnapshotErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"bad width", []byte{31, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}},
+		{"rows missing", []byte{3, 0, 0, 0, 2, 0, 0, 0, 2, 0, 0, 0}},
+		{"stray padding bits", []byte{3, 0, 0, 0, 1, 0, 0, 0, 0xff, 0, 0, 0}},
+	}
+	dir := t.TempDir()
+	for _, c := range cases {
+		path := filepath.Join(dir, "bad.bin")
+		if err := os.WriteFile(path, c.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if b, err := LoadSnapshot(path); err == nil || b != nil {
+			t.Errorf("%s: got a board %v and error %v, want only an error", c.name, b != nil, err)
+		}
+	}
+}
+
+func TestResumeSimulation(t *testing.T) {
+	start := random_board(board_width, board_height, 0.4, 2)
+	cases := []struct {
+		name                        string
+		steps, snapshotEvery, extra int
+	}{
+		{"resume from the last snapshot", 10, 5, 7},
+		{"latest snapshot isn't the last step", 12, 5, 2},
+		{"no extra steps", 6, 3, 0},
+	}
+	for _, c := range cases {
+		dir := t.TempDir()
+		if err := SimulateToDisk(start, c.steps, c.snapshotEvery, dir); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ResumeSimulation(dir, c.extra)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		latest := c.steps - c.steps%c.snapshotEvery
+		if got.CompareTo(forward(start, latest+c.extra), nil) != 0 {
+			t.Errorf("%s: resumed board differs from an uninterrupted %d step run", c.name, latest+c.extra)
+		}
+	}
+
+	if _, err := ResumeSimulation(t.TempDir(), 5); err == nil {
+		t.Error("no snapshots : expected an error")
+	}
+}
+
+func TestBoardJSONRoundTrip(t *testing.T) {
+	cases := []struct {
+		name  string
+		board *Board_BoolPacked
+		wrap  bool
+	}{
+		{"soup", random_board(board_width, board_height, 0.4, 1), false},
+		{"wrapped soup", random_board(board_width, board_height, 0.4, 2), true},
+		{"empty", NewBoard_BoolPacked(board_width, board_height), false},
+		{"widest", random_board(30, 5, 0.5, 3), false},
+		{"single cell", random_board(1, 1, 1, 0), false},
+	}
+	for _, c := range cases {
+		c.board.SetWrap(c.wrap)
+		data, err := json.Marshal(c.board)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(data) > 100+4*c.board.h*4/3 { // The packed rows in base64, not a cell array
+			t.Errorf("%s: %d bytes of JSON for a %dx%d board", c.name, len(data), c.board.w, c.board.h)
+		}
+		var back Board_BoolPacked
+		if err := json.Unmarshal(data, &back); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if back.w != c.board.w || back.h != c.board.h || back.wrap != c.wrap || back.CompareTo(c.board, nil) != 0 {
+			t.Errorf("%s: board changed in the round trip", c.name)
+		}
+		if forward(&back, 3).CompareTo(forward(c.board, 3), nil) != 0 {
+			t.Errorf("%s: the unmarshalled board iterates differently", c.name)
+		}
+	}
+
+	bad := []struct {
+		name, json string
+	}{
+		{"too wide", `{"w":40,"h":2,"rows":""}`},
+		{"no height", `{"w":2,"h":0,"rows":""}`},
+		{"too many rows", `{"w":2,"h":1,"rows":"AAAAAAAAAAA="}`},
+		{"bits past the width", `{"w":2,"h":1,"rows":"/////w=="}`},
+		{"not base64", `{"w":2,"h":1,"rows":"!!"}`},
+		{"not an object", `[1,2]`},
+	}
+	for _, c := range bad {
+		var b Board_BoolPacked
+		if err := json.Unmarshal([]byte(c.json), &b); err == nil {
+			t.Errorf("%s: no error", c.name)
+		}
+	}
+}
+
+func TestLifeProblemJSONRoundTrip(t *testing.T) {
+	training := problem_from_start(7, random_board(board_width, board_height, 0.4, 4), 1)
+	cases := []struct {
+		name    string
+		problem LifeProblem
+	}{
+		{"training", training},
+		{"test", LifeProblem{id: 8, steps: 3, end: training.end}},
+		{"five steps", problem_from_start(9, random_board(board_width, board_height, 0.3, 5), 5)},
+	}
+	for _, c := range cases {
+		data, err := json.Marshal(c.problem)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		var back LifeProblem
+		if err := json.Unmarshal(data, &back); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if back.id != c.problem.id || back.steps != c.problem.steps || back.end.CompareTo(c.problem.end, nil) != 0 {
+			t.Errorf("%s: id, steps or end changed in the round trip", c.name)
+		}
+		if c.problem.start == nil {
+			if back.start != nil || !strings.Contains(string(data), `"start":null`) {
+				t.Errorf("%s: missing start did not round trip as null : %s", c.name, data)
+			}
+			continue
+		}
+		if ok, mismatch := back.Verify(back.start); !ok {
+			t.Errorf("%s: unmarshalled start no longer iterates to the end (mismatch %d)", c.name, mismatch)
+		}
+	}
+}
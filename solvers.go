@@ -0,0 +1,337 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A SolverFunc proposes a start board for the problem.
+// Any randomness must come from r (not the global math/rand), so that runs are reproducible
+type SolverFunc func(problem LifeProblem, r *rand.Rand) *Board_BoolPacked
+
+// DeterministicSolver adapts a solver that needs no randomness to a SolverFunc (e.g. for SolveAll)
+func DeterministicSolver(solve func(problem LifeProblem) *Board_BoolPacked) SolverFunc {
+	return func(problem LifeProblem, r *rand.Rand) *Board_BoolPacked {
+		return solve(problem)
+	}
+}
+
+// ProblemRand returns a generator that depends only on (base_seed, id)
+//   - so a problem gets the same random stream whichever worker picks it up, and whenever
+func ProblemRand(base_seed int64, id int) *rand.Rand {
+	// splitmix64-style scrambling, so that neighbouring ids don't get correlated streams
+	z := uint64(base_seed) + uint64(id)*0x9E3779B97F4A7C15
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	z ^= z >> 31
+	return rand.New(rand.NewSource(int64(z)))
+}
+
+// SolveAll runs solve over every problem in the set using a pool of workers (workers<=0 means one per CPU).
+// Results are identical regardless of the worker count, since each problem derives its own generator.
+// solve is called concurrently, so must allocate its own scratch boards (the problem's boards are only to be read).
+// The error is the first one from s.logger : Every problem is still solved and in the results
+func (s *LifeProblemSet) SolveAll(workers int, base_seed int64, solve SolverFunc) (map[int]*Board_BoolPacked, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// Hand out work in id order (not map order), purely so that progress is easier to follow
+	ids := []int{}
+	for id := range s.problem {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	queue := make(chan int)
+	predictions := make(map[int]*Board_BoolPacked)
+	s.results = make(map[int]SolveResult)
+	var log_err error
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				problem := s.problem[id]
+				begin := time.Now()
+				start := solve(problem, ProblemRand(base_seed, id))
+				duration := time.Since(begin)
+				_, mismatch := problem.Verify(start)
+				result := SolveResult{
+					id:         id,
+					steps:      problem.steps,
+					mismatch:   mismatch,
+					duration:   duration,
+					confidence: 1 - float64(mismatch)/float64(start.w*start.h),
+				}
+				var err error
+				if s.logger != nil {
+					err = s.logger.Log(result)
+				}
+				if s.metric != nil && s.is_training {
+					s.metric.Add(problem.start, start)
+				}
+
+				mutex.Lock()
+				predictions[id] = start
+				s.results[id] = result
+				if err != nil && log_err == nil {
+					log_err = fmt.Errorf("logging problem[%d] : %w", id, err)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for _, id := range ids {
+		queue <- id
+	}
+	close(queue)
+	wg.Wait()
+
+	return predictions, log_err
+}
+
+// ETA projects the time remaining, assuming the rest goes at the same rate as the first 'done' of 'total'.
+// Zero if nothing is done yet (no rate to go on) or everything is
+func ETA(done, total int, elapsed time.Duration) time.Duration {
+	if done <= 0 || done >= total {
+		return 0
+	}
+	return time.Duration(float64(elapsed) * float64(total-done) / float64(done))
+}
+
+// MemoizedSolver wraps a solver so that problems with identical end boards and steps are only solved once :
+// later ones get a copy of the first solution (made with whichever generator the first one was given).
+// The result is safe to use from SolveAll's workers
+func MemoizedSolver(inner SolverFunc) SolverFunc {
+	type memo struct {
+		once  sync.Once
+		start *Board_BoolPacked
+	}
+	var mutex sync.Mutex
+	memos := make(map[board_steps_key]*memo)
+
+	return func(problem LifeProblem, r *rand.Rand) *Board_BoolPacked {
+		key := board_steps_key{board: problem.end.Hash(), steps: problem.steps}
+		mutex.Lock()
+		m, ok := memos[key]
+		if !ok {
+			m = &memo{}
+			memos[key] = m
+		}
+		mutex.Unlock()
+
+		m.once.Do(func() { // Any concurrent requests for the same key wait here for the one solve
+			m.start = inner(problem, r)
+		})
+		return m.start.Clone()
+	}
+}
+
+// Greedy local search : flip single cells of 'start', keeping any flip that reduces the forward mismatch 
+// against 'end', until a whole pass finds no improvement (or max_passes is reached).
+// Returns the improved board (start itself is left alone) and its mismatch
+func hill_climb(start, end *Board_BoolPacked, steps int, max_passes int) (*Board_BoolPacked, int) {
+	l := NewBoardIterator(end.w, end.h)
+	forward_mismatch := func(b *Board_BoolPacked) int {
+		l.current.CopyFrom(b)
+		l.Iterate(steps)
+		return l.current.CompareTo(end, nil)
+	}
+
+	best := NewBoard_BoolPacked(start.w, start.h)
+	best.CopyFrom(start)
+	best_mismatch := forward_mismatch(best)
+
+	for pass := 0; pass < max_passes && best_mismatch > 0; pass++ {
+		improved := false
+		for y := 0; y < best.h; y++ {
+			for x := 0; x < best.w; x++ {
+				best.Set(x, y, !best.isSet(x, y))
+				if m := forward_mismatch(best); m < best_mismatch {
+					best_mismatch = m
+					improved = true
+				} else {
+					best.Set(x, y, !best.isSet(x, y)) // Put it back
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return best, best_mismatch
+}
+
+// SolvePriorGuided starts from the per-cell prior (e.g. training-set frequencies, indexed [y][x]) 
+// thresholded at 0.5, and then hill-climbs to improve the forward match with 'end'
+func SolvePriorGuided(end *Board_BoolPacked, steps int, prior [][]float64) *Board_BoolPacked {
+	start := NewBoard_BoolPacked(end.w, end.h)
+	for y := 0; y < end.h; y++ {
+		for x := 0; x < end.w; x++ {
+			start.Set(x, y, prior[y][x] >= 0.5)
+		}
+	}
+	best, _ := hill_climb(start, end, steps, 10)
+	return best
+}
+
+type beam_entry struct {
+	board    *Board_BoolPacked
+	mismatch int // Forward mismatch of board against the final end
+}
+
+// One-step predecessor candidates for 'target' : an exact one (if a quick brute force finds it),
+// plus local-search approximations grown from the target itself and from a blank board
+func predecessor_candidates(target *Board_BoolPacked) []*Board_BoolPacked {
+	candidates := []*Board_BoolPacked{}
+	if exact, ok := SolveBruteForce(target, 20000, OrderMostConstrainedFirst); ok {
+		candidates = append(candidates, exact)
+	}
+	from_target, _ := hill_climb(target, target, 1, 5)
+	from_blank, _ := hill_climb(NewBoard_BoolPacked(target.w, target.h), target, 1, 5)
+	return append(candidates, from_target, from_blank)
+}
+
+// SolveBeamSearch reverses 'end' one step at a time, keeping the beamWidth best partial chains 
+// (ranked by how well they forward-iterate to 'end') at each depth.
+// Returns the best full-depth start board, and its forward mismatch
+func SolveBeamSearch(end *Board_BoolPacked, steps, beamWidth int) (*Board_BoolPacked, int) {
+	if beamWidth < 1 {
+		beamWidth = 1
+	}
+	beam := []beam_entry{{board: end, mismatch: 0}}
+
+	for depth := 1; depth <= steps; depth++ {
+		problem := LifeProblem{end: end, steps: depth}
+		seen := make(map[string]bool)
+		next := []beam_entry{}
+		for _, entry := range beam {
+			for _, candidate := range predecessor_candidates(entry.board) {
+				key := candidate.toCompactString()
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				_, mismatch := problem.Verify(candidate)
+				next = append(next, beam_entry{board: candidate, mismatch: mismatch})
+			}
+		}
+		sort.SliceStable(next, func(i, j int) bool {
+			return next[i].mismatch < next[j].mismatch
+		})
+		if len(next) > beamWidth {
+			next = next[:beamWidth]
+		}
+		beam = next
+	}
+	return beam[0].board, beam[0].mismatch
+}
+
+// Solve proposes up to max_candidates start boards for the problem, best first, ranked by how closely they 
+// forward-iterate to p.end (Verify gives each one's mismatch, for thresholding).
+// For steps==1 : an exact predecessor if the (budgeted) cell-by-cell brute force finds one, plus local-search 
+// approximations grown from the cells that constraint propagation forces, from the end board, and from a blank board.
+// Longer problems go through SolveBeamSearch, one step at a time.
+// When no exact predecessor exists (or is found), the closest approximations still come back
+func (p *LifeProblem) Solve(max_candidates int) []*Board_BoolPacked {
+	candidates := []*Board_BoolPacked{}
+	if p.steps == 1 {
+		// Which enumeration order thrashes depends on the board, so give each a turn
+		for _, order := range []EnumerationOrder{OrderMostConstrainedFirst, OrderRowMajor, OrderSpiral} {
+			if exact, ok := SolveBruteForce(p.end, 20000, order); ok {
+				candidates = append(candidates, exact)
+				break
+			}
+		}
+		from_end, _ := hill_climb(p.end, p.end, 1, 5)
+		from_blank, _ := hill_climb(NewBoard_BoolPacked(p.end.w, p.end.h), p.end, 1, 5)
+		candidates = append(candidates, from_end, from_blank)
+
+		// Seed from the forced cells (free cells start dead)
+		if cd, ok := PropagateConstraints(p.end); ok {
+			forced := NewBoard_BoolPacked(p.end.w, p.end.h)
+			for y := 0; y < p.end.h; y++ {
+				for x := 0; x < p.end.w; x++ {
+					forced.Set(x, y, cd.d[y][x] == domain_alive)
+				}
+			}
+			from_forced, _ := hill_climb(forced, p.end, 1, 5)
+			candidates = append(candidates, from_forced)
+		}
+	} else {
+		from_beam, _ := SolveBeamSearch(p.end, p.steps, 2)
+		from_end, _ := hill_climb(p.end, p.end, p.steps, 5)
+		candidates = append(candidates, from_beam, from_end)
+	}
+
+	// Best first, without duplicates
+	ranked := []beam_entry{}
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		key := candidate.toCompactString()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		_, mismatch := p.Verify(candidate)
+		ranked = append(ranked, beam_entry{board: candidate, mismatch: mismatch})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].mismatch < ranked[j].mismatch
+	})
+
+	result := []*Board_BoolPacked{}
+	for i := 0; i < len(ranked) && i < max_candidates; i++ {
+		result = append(result, ranked[i].board)
+	}
+	return result
+}
+
+// SimulateAll forward-iterates every problem's start by its steps over a pool of workers, 
+// and returns the wall time taken.  Purely a performance harness for the simulation engine.
+// Problems without a start (i.e. test data) are skipped
+func SimulateAll(s *LifeProblemSet, workers int) time.Duration {
+	if workers < 1 {
+		workers = 1
+	}
+	begin := time.Now()
+
+	queue := make(chan LifeProblem)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l := NewBoardIterator(board_width, board_height) // Each worker has its own scratch boards
+			for problem := range queue {
+				if l.current.w != problem.start.w || l.current.h != problem.start.h {
+					l = NewBoardIterator(problem.start.w, problem.start.h)
+				}
+				l.current.CopyFrom(problem.start)
+				l.Iterate(problem.steps)
+			}
+		}()
+	}
+	for _, problem := range s.problem {
+		if problem.start == nil {
+			continue
+		}
+		queue <- problem
+	}
+	close(queue)
+	wg.Wait()
+
+	return time.Since(begin)
+}
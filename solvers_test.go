@@ -0,0 +1,303 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A test set of 'count' problems with random ends (no starts)
+func random_test_set(count int) *LifeProblemSet {
+	s := &LifeProblemSet{problem: map[int]LifeProblem{}}
+	for id := 1; id <= count; id++ {
+		s.problem[id] = LifeProblem{id: id, end: random_board(board_width, board_height, 0.2, int64(id)), steps: 1 + id%5}
+	}
+	return s
+}
+
+// A solver whose answer depends only on the generator it is handed
+func random_solver(problem LifeProblem, r *rand.Rand) *Board_BoolPacked {
+	b := NewBoard_BoolPacked(problem.end.w, problem.end.h)
+	for y := 0; y < b.h; y++ {
+		for x := 0; x < b.w; x++ {
+			b.Set(x, y, r.Float32() < 0.5)
+		}
+	}
+	return b
+}
+
+func TestSolveAllDeterministic(t *testing.T) {
+	s := random_test_set(30)
+	want, _ := s.SolveAll(1, 42, random_solver)
+	for _, workers := range []int{1, 2, 8, 0} {
+		got, _ := s.SolveAll(workers, 42, random_solver)
+		for id := range s.problem {
+			if got[id].toCompactString() != want[id].toCompactString() {
+				t.Fatalf("workers=%d: problem[%d] differs from the single-worker run", workers, id)
+			}
+		}
+	}
+	if want[1].toCompactString() == want[2].toCompactString() {
+		t.Error("different ids got the same random stream")
+	}
+	if other, _ := s.SolveAll(8, 43, random_solver); other[1].toCompactString() == want[1].toCompactString() {
+		t.Error("a different base seed gave the same prediction")
+	}
+}
+
+// A generated training problem : a random soup settled for 5 generations, then 'steps' more
+func settled_problem(id int, w, h int, steps int, seed int64) LifeProblem {
+	return problem_from_start(id, forward(random_board(w, h, 0.4, seed), 5), steps)
+}
+
+func TestSolvePriorGuided(t *testing.T) {
+	lean_on_end := func(live, dead float64) func(end *Board_BoolPacked, x, y int) float64 {
+		return func(end *Board_BoolPacked, x, y int) float64 {
+			if end.isSet(x, y) {
+				return live
+			}
+			return dead
+		}
+	}
+	still_lifes := board_from_rows(board_width, board_height, "", "-XX-----XX", "-XX----X--X", "--------XX", "", "", "----X", "---X-X", "----X")
+	cases := []struct {
+		name    string
+		problem LifeProblem
+		prior   func(end *Board_BoolPacked, x, y int) float64
+	}{
+		{"settled soup, strong prior", settled_problem(1, board_width, board_height, 1, 9), lean_on_end(0.8, 0.1)},
+		{"settled soup, weak prior", settled_problem(2, board_width, board_height, 1, 11), lean_on_end(0.6, 0.3)},
+		{"settled soup, another board", settled_problem(3, board_width, board_height, 1, 12), lean_on_end(0.8, 0.1)},
+		{"still lifes", problem_from_start(4, still_lifes, 1), lean_on_end(0.9, 0.05)},
+	}
+	for _, c := range cases {
+		prior := make([][]float64, board_height)
+		for y := range prior {
+			prior[y] = make([]float64, board_width)
+			for x := range prior[y] {
+				prior[y][x] = c.prior(c.problem.end, x, y)
+			}
+		}
+		_, guided := c.problem.Verify(SolvePriorGuided(c.problem.end, 1, prior))
+		_, identity := c.problem.Verify(c.problem.end)
+		// Identity is already exact for still lifes : there the solver just mustn't lose that
+		if guided > identity || guided == identity && identity > 0 {
+			t.Errorf("%s: prior-guided mismatch %d, identity %d", c.name, guided, identity)
+		}
+	}
+}
+
+func TestSolveBeamSearch(t *testing.T) {
+	cases := []struct {
+		name      string
+		seed      int64
+		beamWidth int
+	}{
+		{"narrow beam", 11, 1},
+		{"wider beam", 11, 3},
+		{"another board", 12, 2},
+	}
+	for _, c := range cases {
+		problem := settled_problem(1, board_width, board_height, 3, c.seed)
+		start, mismatch := SolveBeamSearch(problem.end, 3, c.beamWidth)
+		_, verified := problem.Verify(start)
+		if verified != mismatch {
+			t.Errorf("%s: reported mismatch %d, but the start verifies with %d", c.name, mismatch, verified)
+		}
+		if _, identity := problem.Verify(problem.end); mismatch >= identity {
+			t.Errorf("%s: beam search mismatch %d, identity %d", c.name, mismatch, identity)
+		}
+	}
+}
+
+// A training set of 'count' settled problems, with 1..5 steps
+func random_training_set(count int) *LifeProblemSet {
+	s := &LifeProblemSet{problem: map[int]LifeProblem{}, is_training: true}
+	for id := 1; id <= count; id++ {
+		s.problem[id] = settled_problem(id, board_width, board_height, 1+id%5, int64(id))
+	}
+	return s
+}
+
+func TestSimulateAll(t *testing.T) {
+	mixed := random_training_set(10)
+	for id, p := range random_test_set(5).problem {
+		mixed.problem[100+id] = p
+	}
+	cases := []struct {
+		name    string
+		set     *LifeProblemSet
+		workers int
+	}{
+		{"training set", random_training_set(20), 4},
+		{"single worker", random_training_set(5), 1},
+		{"zero workers means one", random_training_set(5), 0},
+		{"test set has no starts", random_test_set(10), 4},
+		{"mixed", mixed, 3},
+		{"empty", &LifeProblemSet{problem: map[int]LifeProblem{}}, 2},
+	}
+	for _, c := range cases {
+		if d := SimulateAll(c.set, c.workers); d <= 0 {
+			t.Errorf("%s: SimulateAll() took %v, want a positive duration", c.name, d)
+		}
+	}
+}
+
+func BenchmarkSimulateAll(b *testing.B) {
+	s := random_training_set(200)
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				SimulateAll(s, workers)
+			}
+		})
+	}
+}
+
+func TestETA(t *testing.T) {
+	cases := []struct {
+		name        string
+		done, total int
+		elapsed     time.Duration
+		want        time.Duration
+	}{
+		{"half done", 50, 100, 3 * time.Minute, 3 * time.Minute},
+		{"a quarter done", 25, 100, time.Minute, 3 * time.Minute},
+		{"nearly done", 99, 100, 99 * time.Second, time.Second},
+		{"nothing done", 0, 100, time.Minute, 0},
+		{"all done", 100, 100, time.Minute, 0},
+	}
+	for _, c := range cases {
+		if got := ETA(c.done, c.total, c.elapsed); got != c.want {
+			t.Errorf("%s: ETA() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestLifeProblemSolve(t *testing.T) {
+	blinker_block := board_from_rows(board_width, board_height, "", "", "", "", "", "-----XXX",
+		"", "", "", "", "----------XX", "----------XX")
+	cases := []struct {
+		name    string
+		problem LifeProblem
+		max     int
+		exact   bool // An exact predecessor is known to exist, and Solve should find it
+		none    bool // No exact predecessor exists
+	}{
+		{"blinker and block", problem_from_start(1, blinker_block, 1), 3, true, false},
+		{"unique 4x4 predecessor", problem_from_start(2, board_from_rows(4, 4, "--XX", "XX--", "--XX", "X---"), 1), 5, true, false},
+		{"single candidate", problem_from_start(3, blinker_block, 1), 1, true, false},
+		{"empty end", LifeProblem{id: 4, end: NewBoard_BoolPacked(board_width, board_height), steps: 1}, 3, true, false},
+		// On a 3x1 board no cell ever has 3 neighbours, and only the middle one can have 2 : "X-X" has no predecessor
+		{"no predecessor", LifeProblem{id: 5, end: board_from_rows(3, 1, "X-X"), steps: 1}, 4, false, true},
+		{"settled soup", settled_problem(6, board_width, board_height, 1, 3), 4, false, false},
+		{"three steps", settled_problem(7, board_width, board_height, 3, 4), 2, false, false},
+	}
+	for _, c := range cases {
+		candidates := c.problem.Solve(c.max)
+		if len(candidates) == 0 || len(candidates) > c.max {
+			t.Errorf("%s: got %d candidates, want 1..%d", c.name, len(candidates), c.max)
+			continue
+		}
+		mismatches := []int{}
+		for _, candidate := range candidates {
+			_, mismatch := c.problem.Verify(candidate)
+			mismatches = append(mismatches, mismatch)
+		}
+		for i := 1; i < len(mismatches); i++ {
+			if mismatches[i-1] > mismatches[i] {
+				t.Errorf("%s: candidates not ranked best first : %v", c.name, mismatches)
+			}
+		}
+		if c.exact && mismatches[0] != 0 {
+			t.Errorf("%s: best candidate misses by %d, want an exact predecessor", c.name, mismatches[0])
+		}
+		if c.none && mismatches[0] == 0 {
+			t.Errorf("%s: found an exact predecessor that cannot exist", c.name)
+		}
+		if _, identity := c.problem.Verify(c.problem.end); mismatches[0] > identity {
+			t.Errorf("%s: best candidate misses by %d, worse than the end board itself (%d)", c.name, mismatches[0], identity)
+		}
+	}
+}
+
+func TestMemoizedSolver(t *testing.T) {
+	a, b := random_board(board_width, board_height, 0.3, 1), random_board(board_width, board_height, 0.3, 2)
+	a_copy := random_board(board_width, board_height, 0.3, 1)
+	cases := []struct {
+		name     string
+		problems []LifeProblem
+		calls    int32
+	}{
+		{"identical end and steps", []LifeProblem{{end: a, steps: 2}, {end: a_copy, steps: 2}}, 1},
+		{"same end, different steps", []LifeProblem{{end: a, steps: 2}, {end: a, steps: 3}}, 2},
+		{"different ends", []LifeProblem{{end: a, steps: 2}, {end: b, steps: 2}}, 2},
+		{"many repeats", []LifeProblem{{end: a, steps: 1}, {end: b, steps: 1}, {end: a, steps: 1}, {end: b, steps: 1}, {end: a, steps: 1}, {end: a, steps: 4}}, 3},
+	}
+	for _, c := range cases {
+		for _, workers := range []int{1, 8} {
+			var calls int32
+			inner := func(problem LifeProblem, r *rand.Rand) *Board_BoolPacked {
+				atomic.AddInt32(&calls, 1)
+				return forward(problem.end, problem.steps)
+			}
+			s := &LifeProblemSet{problem: map[int]LifeProblem{}}
+			for i, problem := range c.problems {
+				problem.id = i
+				s.problem[i] = problem
+			}
+			predictions, err := s.SolveAll(workers, 0, MemoizedSolver(inner))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if calls != c.calls {
+				t.Errorf("%s, workers=%d: inner solver called %d times, want %d", c.name, workers, calls, c.calls)
+			}
+			for id, problem := range s.problem {
+				if predictions[id].CompareTo(forward(problem.end, problem.steps), nil) != 0 {
+					t.Errorf("%s, workers=%d: problem %d got another problem's solution", c.name, workers, id)
+				}
+			}
+
+			// Each caller gets its own copy of a shared solution
+			predictions[0].Set(0, 0, !predictions[0].isSet(0, 0))
+			for id := 1; id < len(c.problems); id++ {
+				if predictions[id].CompareTo(forward(c.problems[id].end, c.problems[id].steps), nil) != 0 {
+					t.Errorf("%s, workers=%d: problems 0 and %d share a board", c.name, workers, id)
+				}
+			}
+		}
+	}
+}
+
+// The pool of workers must give exactly the serial answers, for a deterministic solver
+func TestSolveAllParallel(t *testing.T) {
+	s := random_training_set(40)
+	hill := DeterministicSolver(func(problem LifeProblem) *Board_BoolPacked {
+		start, _ := hill_climb(problem.end, problem.end, problem.steps, 2)
+		return start
+	})
+	serial := map[int]*Board_BoolPacked{}
+	for id, problem := range s.problem {
+		serial[id] = hill(problem, nil)
+	}
+	for _, workers := range []int{1, 3, 16, 0, -1} {
+		parallel, err := s.SolveAll(workers, 0, hill)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parallel) != len(serial) {
+			t.Errorf("workers=%d: %d predictions, want %d", workers, len(parallel), len(serial))
+		}
+		for id := range serial {
+			if parallel[id] == nil || parallel[id].CompareTo(serial[id], nil) != 0 {
+				t.Errorf("workers=%d: problem %d differs from the serial result", workers, id)
+			}
+		}
+	}
+}
@@ -5,7 +5,9 @@ package main
 
 import (
 	"fmt"
+	"math/bits"
 	"math/rand"
+	"sync"
 )
 
 
@@ -16,6 +18,7 @@ const board_height int =20
 type Board_BoolPacked struct {
 	s    []int32
 	h,w  int // Only used for GENERIC functions
+	wrap bool // Toroidal boundary (default : everything outside the board is dead, as in the Kaggle data)
 }
 
 var count_bits_array [512]byte
@@ -35,21 +38,113 @@ func build_count_bits_array() {
 
 var board_empty *Board_BoolPacked
 
+// The package-level math/rand functions, wrapped up as a *rand.Rand :
+//   Each randomized function has an ...R(r *rand.Rand) variant so that concurrent runs can have their own sources,
+//   and the original signature just passes rand_global (so rand.Seed() behaves as it always did)
+type global_source struct{}
+
+func (global_source) Int63() int64    { return rand.Int63() }
+func (global_source) Uint64() uint64  { return rand.Uint64() }
+func (global_source) Seed(seed int64) { rand.Seed(seed) }
+
+var rand_global = rand.New(global_source{})
+
 // NewBoard_BoolArray returns an empty field of the specified width and height.
 func NewBoard_BoolPacked(w,h int) *Board_BoolPacked { // OPTIMIZED FOR BoolPacked
-	if board_width > 22 {
-		fmt.Print("TOO LARGE AN ARRAY for bottom 3 bytes of int32!\n")
+	if w > 30 {
+		fmt.Print("TOO LARGE AN ARRAY for a padded int32 row!\n")
 	}
 	
-	s := make([]int32, board_height+2) // Need padding before and after
-	return &Board_BoolPacked{s: s, h:board_height, w:board_width}
+	s := make([]int32, h+2) // Need padding before and after
+	return &Board_BoolPacked{s: s, h:h, w:w}
 }
 
+// Clear kills every cell (in place)
+func (f *Board_BoolPacked) Clear() { // OPTIMIZED FOR BoolPacked
+	for y := range f.s {
+		f.s[y] = 0
+	}
+}
+
+// BoardPool recycles scratch boards of one size, to cut down on allocation (and GC) in the solvers' inner loops.
+// Safe to share between goroutines
+type BoardPool struct {
+	w, h int
+	pool sync.Pool
+}
+
+func NewBoardPool(w, h int) *BoardPool {
+	p := &BoardPool{w: w, h: h}
+	p.pool.New = func() interface{} {
+		return NewBoard_BoolPacked(w, h)
+	}
+	return p
+}
+
+// For the standard (Kaggle) board size
+var StandardBoardPool = NewBoardPool(board_width, board_height)
+
+// Get returns a cleared board (with no wrap)
+func (p *BoardPool) Get() *Board_BoolPacked {
+	b := p.pool.Get().(*Board_BoolPacked)
+	b.Clear()
+	b.wrap = false
+	return b
+}
+
+// Put hands a board back for reuse, so it mustn't be touched afterwards.  Boards of other sizes are left to the GC
+func (p *BoardPool) Put(b *Board_BoolPacked) {
+	if b.w == p.w && b.h == p.h {
+		p.pool.Put(b)
+	}
+}
+
+// CopyFrom overwrites dest in place (its rows may be part of a shared arena), so the boards must be the same size
 func (dest *Board_BoolPacked) CopyFrom(src *Board_BoolPacked) { // OPTIMIZED FOR BoolPacked
-	dest.s = make([]int32, board_height+2)
-	for y := 0; y<board_height+2; y++ {
-		dest.s[y] = src.s[y]
+	dest.must_match_dimensions(src)
+	copy(dest.s, src.s)
+	dest.wrap = src.wrap
+}
+
+// Clone returns an independent copy of f
+func (f *Board_BoolPacked) Clone() *Board_BoolPacked {
+	clone := NewBoard_BoolPacked(f.w, f.h)
+	clone.CopyFrom(f)
+	return clone
+}
+
+// SetWrap switches between a toroidal boundary (neighbours wrap around modulo width/height), and the default dead one
+func (f *Board_BoolPacked) SetWrap(wrap bool) {
+	f.wrap = wrap
+}
+
+// Alive reports whether the specified cell is alive, wrapping the coordinates around the board
+func (f *Board_BoolPacked) isSet_wrap(x, y int) bool {
+	return f.isSet(((x%f.w)+f.w)%f.w, ((y%f.h)+f.h)%f.h)
+}
+
+// Validate checks the packed representation is well-formed : Positive dimensions, one word per row plus
+// the two padding rows, and no stray bits in the padding (which would throw off the bit-counting)
+func (f *Board_BoolPacked) Validate() error { // OPTIMIZED FOR BoolPacked
+	if f.w <= 0 || f.h <= 0 {
+		return fmt.Errorf("board has non-positive dimensions %dx%d", f.w, f.h)
 	}
+	if f.w > 30 {
+		return fmt.Errorf("board width %d too large for a padded int32 row", f.w)
+	}
+	if len(f.s) != f.h+2 {
+		return fmt.Errorf("board has %d packed rows, expected %d", len(f.s), f.h+2)
+	}
+	if f.s[0] != 0 || f.s[f.h+1] != 0 {
+		return fmt.Errorf("board has bits set in the top/bottom padding rows")
+	}
+	valid := int32(((uint32(1) << uint(f.w)) - 1) << 1)
+	for y := 1; y <= f.h; y++ {
+		if f.s[y] & ^valid != 0 {
+			return fmt.Errorf("board row %d has bits set in the padding columns", y-1)
+		}
+	}
+	return nil
 }
 
 // Set sets the state of the specified cell to the given value.
@@ -69,7 +164,40 @@ func (f *Board_BoolPacked) isSet(x, y int) bool { // OPTIMIZED FOR BoolPacked
 }
 
 // Update the state of the next field (next) in-place from the current field (f).
+// Each row is done a whole word at a time : The 8 neighbours of every cell are the rows above/below/itself shifted
+// by a column either way, and these are summed bit-wise (a bit-sliced counter, mod 8) in ones/twos/fours words
 func (f *Board_BoolPacked) Iterate(next *Board_BoolPacked) { // OPTIMIZED FOR BoolPacked
+	next.wrap = f.wrap
+	if f.wrap {
+		// The padding rows/columns are what make the fast path work, and they are always dead
+		f.Iterate_Generic(next)
+		return
+	}
+
+	valid := ((uint32(1) << uint(f.w)) - 1) << 1 // Bits 1..w : Keeps the padding columns clear
+
+	next.s[0] = 0
+	for r := 1; r <= f.h; r++ {
+		// uint32, so that the right shifts don't smear the sign bit
+		top, mid, bot := uint32(f.s[r-1]), uint32(f.s[r]), uint32(f.s[r+1])
+		neighbours := [8]uint32{top<<1, top, top>>1, mid<<1, mid>>1, bot<<1, bot, bot>>1}
+
+		ones, twos, fours := uint32(0), uint32(0), uint32(0)
+		for _, n := range neighbours {
+			carry := ones & n
+			ones ^= n
+			fours ^= twos & carry // 8 neighbours wraps round to 0 : dead either way
+			twos ^= carry
+		}
+
+		// exactly 3 neighbors (011): on,  exactly 2 neighbors (010): maintain current state,  otherwise: off.
+		next.s[r] = int32(twos &^ fours & (ones | mid) & valid)
+	}
+	next.s[f.h+1] = 0
+}
+
+// The original column-at-a-time Iterate() : A 512-entry bit-count lookup for each cell
+func (f *Board_BoolPacked) Iterate_ByColumn(next *Board_BoolPacked) { // OPTIMIZED FOR BoolPacked
 	// This is done rather over-efficiently...
 
 	// These are constants - the game bits pass over them
@@ -80,7 +208,7 @@ func (f *Board_BoolPacked) Iterate(next *Board_BoolPacked) { // OPTIMIZED FOR Bo
 	current_filter := int32(2) //  010
 
 	next.s[0] = 0
-	for r := 1; r <= board_height; r++ {
+	for r := 1; r <= f.h; r++ {
 		r_top := f.s[r-1]
 		r_mid := f.s[r]
 		r_bot := f.s[r+1]
@@ -88,7 +216,7 @@ func (f *Board_BoolPacked) Iterate(next *Board_BoolPacked) { // OPTIMIZED FOR Bo
 		acc := int32(0)
 		p := int32(2) // Start in the middle row, one column in (000000010b)
 
-		for c := 1; c <= board_width; c++ {
+		for c := 1; c <= f.w; c++ {
 			cnt := count_bits_array[((r_top&top_filter)<<6)|
 									((r_mid&mid_filter)<<3)|
 									((r_bot&bot_filter))    ]
@@ -115,19 +243,20 @@ func (f *Board_BoolPacked) Iterate(next *Board_BoolPacked) { // OPTIMIZED FOR Bo
 		}
 		next.s[r] = acc
 	}
-	next.s[board_height+1] = 0
+	next.s[f.h+1] = 0
 }
 
 func (attempt *Board_BoolPacked) CompareTo(target *Board_BoolPacked, diff *Board_BoolPacked) int { // OPTIMIZED FOR BoolPacked
 	r := 0
 	match := int32(0)
 	lowest_byte := int32(0xff)
-	for y := 1; y<=board_height; y++ {
-		match = attempt.s[y] ^ target.s[y] // This covers all of lower 24 bits, which is what we care about
-		if match>0 {
+	for y := 1; y<=attempt.h; y++ {
+		match = attempt.s[y] ^ target.s[y] // This covers all 32 bits (the padding bits are always zero)
+		if match!=0 {
 			r += int(count_bits_array[(match>>0) & lowest_byte] + 
 					 count_bits_array[(match>>8) & lowest_byte] + 
-					 count_bits_array[(match>>16) & lowest_byte])
+					 count_bits_array[(match>>16) & lowest_byte] + 
+					 count_bits_array[(match>>24) & lowest_byte])
 		}
 		if diff != nil {
 			diff.s[y]=match
@@ -136,36 +265,154 @@ func (attempt *Board_BoolPacked) CompareTo(target *Board_BoolPacked, diff *Board
 	return r
 }
 
+// Hash is a 64-bit FNV-1a over the dimensions and the packed rows : Equal boards always hash the same 
+// (the padding is always zero), and different ones practically never do
+func (f *Board_BoolPacked) Hash() uint64 { // OPTIMIZED FOR BoolPacked
+	const fnv_offset, fnv_prime = 14695981039346656037, 1099511628211
+	h := uint64(fnv_offset)
+	mix := func(word uint32) {
+		for i := uint(0); i < 32; i += 8 {
+			h ^= uint64((word >> i) & 0xff)
+			h *= fnv_prime
+		}
+	}
+	mix(uint32(f.w))
+	mix(uint32(f.h))
+	for y := 1; y <= f.h; y++ {
+		mix(uint32(f.s[y]))
+	}
+	return h
+}
+
+func (f *Board_BoolPacked) must_match_dimensions(other *Board_BoolPacked) {
+	if f.w != other.w || f.h != other.h {
+		panic(fmt.Sprintf("board dimensions differ : %dx%d vs %dx%d", f.w, f.h, other.w, other.h))
+	}
+}
+
+// HammingDistance counts the cells that differ between two boards of the same size (panics otherwise)
+func (f *Board_BoolPacked) HammingDistance(other *Board_BoolPacked) int { // OPTIMIZED FOR BoolPacked
+	f.must_match_dimensions(other)
+	return f.CompareTo(other, nil)
+}
+
+// Diff returns a board with the cells set where the two (same size) boards differ
+func (f *Board_BoolPacked) Diff(other *Board_BoolPacked) *Board_BoolPacked {
+	return f.Xor(other)
+}
+
+// Combines two (same size) boards row-word by row-word into a new one.  Padding stays dead so long as op(0,0)==0
+func (f *Board_BoolPacked) combine(other *Board_BoolPacked, op func(a, b int32) int32) *Board_BoolPacked { // OPTIMIZED FOR BoolPacked
+	f.must_match_dimensions(other)
+	result := NewBoard_BoolPacked(f.w, f.h)
+	for y := 1; y<=f.h; y++ {
+		result.s[y] = op(f.s[y], other.s[y])
+	}
+	return result
+}
+
+// And : cells alive on both boards
+func (f *Board_BoolPacked) And(other *Board_BoolPacked) *Board_BoolPacked {
+	return f.combine(other, func(a, b int32) int32 { return a & b })
+}
+
+// Or : cells alive on either board
+func (f *Board_BoolPacked) Or(other *Board_BoolPacked) *Board_BoolPacked {
+	return f.combine(other, func(a, b int32) int32 { return a | b })
+}
+
+// Xor : cells alive on just one of the boards
+func (f *Board_BoolPacked) Xor(other *Board_BoolPacked) *Board_BoolPacked {
+	return f.combine(other, func(a, b int32) int32 { return a ^ b })
+}
+
+// AndNot : cells alive on f but not on other
+func (f *Board_BoolPacked) AndNot(other *Board_BoolPacked) *Board_BoolPacked {
+	return f.combine(other, func(a, b int32) int32 { return a &^ b })
+}
+
+
+// Population returns the number of live cells
+func (f *Board_BoolPacked) Population() int { // OPTIMIZED FOR BoolPacked
+	r := 0
+	for y := 1; y<=f.h; y++ { // Not CompareTo(board_empty) : that is only board_width x board_height
+		r += bits.OnesCount32(uint32(f.s[y])) // Padding bits are never set
+	}
+	return r
+}
+
+// BoundingBox returns the tightest rectangle (minX,minY)-(maxX,maxY) inclusive that holds every live cell.
+// empty is true (and the rest zero) for a blank board
+func (f *Board_BoolPacked) BoundingBox() (minX, minY, maxX, maxY int, empty bool) { // OPTIMIZED FOR BoolPacked
+	columns := uint32(0) // All the rows OR'd together
+	minY, maxY = -1, -1
+	for y := 1; y<=f.h; y++ {
+		if f.s[y] != 0 {
+			if minY < 0 {
+				minY = y-1
+			}
+			maxY = y-1
+			columns |= uint32(f.s[y])
+		}
+	}
+	if columns == 0 {
+		return 0, 0, 0, 0, true
+	}
+	// Cell x is at bit x+1
+	minX = bits.TrailingZeros32(columns) - 1
+	maxX = 31 - bits.LeadingZeros32(columns) - 1
+	return minX, minY, maxX, maxY, false
+}
+
+// Density is the fraction of cells that are alive
+func (f *Board_BoolPacked) Density() float32 {
+	if f.w*f.h == 0 {
+		return 0
+	}
+	return float32(f.Population()) / float32(f.w*f.h)
+}
 
 
 func (f *Board_BoolPacked) MutateFlipBits(count int) {
+	f.MutateFlipBitsR(count, rand_global)
+}
+
+func (f *Board_BoolPacked) MutateFlipBitsR(count int, r *rand.Rand) {
 	for c:=0; c<count; c++ {
 		// Pick two random locations, and copy the bit from one to the other
-		src_x, src_y := rand.Intn(f.w), rand.Intn(f.h)
-		dst_x, dst_y := rand.Intn(f.w), rand.Intn(f.h)
+		src_x, src_y := r.Intn(f.w), r.Intn(f.h)
+		dst_x, dst_y := r.Intn(f.w), r.Intn(f.h)
 		
 		f.Set(dst_x, dst_y, f.isSet(src_x, src_y))
 	}
 }
 
 func CoordWithinRadius(origin int, dim int, radius int) int {
+	return CoordWithinRadiusR(origin, dim, radius, rand_global)
+}
+
+func CoordWithinRadiusR(origin int, dim int, radius int, r *rand.Rand) int {
 	q :=-1
-	for ; (q<0 || q>=dim); q = origin+rand.Intn(radius*2+1)-radius {
+	for ; (q<0 || q>=dim); q = origin+r.Intn(radius*2+1)-radius {
 	}
 	return q
 }
 
 func (f *Board_BoolPacked) MutateRadiusBits_SwitchARoo(another_mutation_pct, radius int) {
+	f.MutateRadiusBits_SwitchARooR(another_mutation_pct, radius, rand_global)
+}
+
+func (f *Board_BoolPacked) MutateRadiusBits_SwitchARooR(another_mutation_pct, radius int, r *rand.Rand) {
 	// Pick a random location
-	src_x, src_y := rand.Intn(f.w), rand.Intn(f.h)
+	src_x, src_y := r.Intn(f.w), r.Intn(f.h)
 	for {
-		if rand.Intn(100)>another_mutation_pct {
+		if r.Intn(100)>another_mutation_pct {
 			break
 		}
 			
 		// and another within L1(radius) of it
-		dst_x := CoordWithinRadius(src_x, f.w, radius)
-		dst_y := CoordWithinRadius(src_y, f.h, radius)
+		dst_x := CoordWithinRadiusR(src_x, f.w, radius, r)
+		dst_y := CoordWithinRadiusR(src_y, f.h, radius, r)
 
 		src_isSet := f.isSet(src_x, src_y)
 		// Switch-a-roo
@@ -175,12 +422,16 @@ func (f *Board_BoolPacked) MutateRadiusBits_SwitchARoo(another_mutation_pct, rad
 }
 
 func (f *Board_BoolPacked) MutateRadiusBits(another_mutation_pct, radius int) {
+	f.MutateRadiusBitsR(another_mutation_pct, radius, rand_global)
+}
+
+func (f *Board_BoolPacked) MutateRadiusBitsR(another_mutation_pct, radius int, r *rand.Rand) {
 	// Pick a random location
-	src_x, src_y := rand.Intn(f.w), rand.Intn(f.h)
+	src_x, src_y := r.Intn(f.w), r.Intn(f.h)
 	for {
 		// Pick an L1 radius
-		r_up := rand.Intn(radius)
-		r_down := rand.Intn(radius)
+		r_up := r.Intn(radius)
+		r_down := r.Intn(radius)
 		for x:=src_x-r_down; x<=src_x+r_up; x++ {
 			for y:=src_y-r_down; y<=src_y+r_up; y++ {
 				if 0<=x && x<f.w && 0<=y && y<f.h {
@@ -188,20 +439,24 @@ func (f *Board_BoolPacked) MutateRadiusBits(another_mutation_pct, radius int) {
 				}
 			}
 		}
-		if rand.Intn(100)>another_mutation_pct {
+		if r.Intn(100)>another_mutation_pct {
 			break
 		}
 	}
 }
 
-func (mask *Board_BoolPacked) RandomBitPosition() (int, int) { // OPTIMIZED FOR BoolPacked
+func (mask *Board_BoolPacked) RandomBitPosition() (int, int) {
+	return mask.RandomBitPositionR(rand_global)
+}
+
+func (mask *Board_BoolPacked) RandomBitPositionR(r *rand.Rand) (int, int) { // OPTIMIZED FOR BoolPacked
 	// This isn't really a uniform picker amongst mask bits, but it makes an effort to be fast...
 	// Pick a random row, and find the first line there (or after) that has a non-zero in it
-	y := rand.Intn(board_height)
-	for cnt := board_height; (mask.s[y+1]==0) && cnt>0; cnt-- {
+	y := r.Intn(mask.h)
+	for cnt := mask.h; (mask.s[y+1]==0) && cnt>0; cnt-- {
 		//fmt.Printf("MutateMask moving to next line %2d (count=%2d)\n", y, cnt)
 		y++
-		if y>=board_height {
+		if y>=mask.h {
 			//fmt.Printf("MutateMask wraparound after line %2d\n", y)
 			y=0
 		}
@@ -215,10 +470,10 @@ func (mask *Board_BoolPacked) RandomBitPosition() (int, int) { // OPTIMIZED FOR
 	}
 	
 	// Pick a random column
-	x := rand.Intn(board_width)
-	for cnt := board_width; ((mask_row & (1<<uint(x+1)))==0) && cnt>0; cnt-- {
+	x := r.Intn(mask.w)
+	for cnt := mask.w; ((mask_row & (1<<uint(x+1)))==0) && cnt>0; cnt-- {
 		x++
-		if x>=board_width {
+		if x>=mask.w {
 			x=0
 		}
 	}
@@ -231,9 +486,13 @@ func (mask *Board_BoolPacked) RandomBitPosition() (int, int) { // OPTIMIZED FOR
 	return x,y
 }
 
-func (f *Board_BoolPacked) MutateMask(mask *Board_BoolPacked, another_mutation_pct, radius int) { // OPTIMIZED FOR BoolPacked
+func (f *Board_BoolPacked) MutateMask(mask *Board_BoolPacked, another_mutation_pct, radius int) {
+	f.MutateMaskR(mask, another_mutation_pct, radius, rand_global)
+}
+
+func (f *Board_BoolPacked) MutateMaskR(mask *Board_BoolPacked, another_mutation_pct, radius int, r *rand.Rand) { // OPTIMIZED FOR BoolPacked
 	for {
-		x,y := mask.RandomBitPosition()
+		x,y := mask.RandomBitPositionR(r)
 		
 		if x<0 || y<0 {
 			break
@@ -241,12 +500,12 @@ func (f *Board_BoolPacked) MutateMask(mask *Board_BoolPacked, another_mutation_p
 		
 		//f.Set(x,y, f.isSet(x,y)==false) // Flip the bit which corresponds to the diff
 		
-		x_offset := CoordWithinRadius(x, board_width, radius)
-		y_offset := CoordWithinRadius(y, board_height, radius)
+		x_offset := CoordWithinRadiusR(x, f.w, radius, r)
+		y_offset := CoordWithinRadiusR(y, f.h, radius, r)
 		f.Set(x_offset,y_offset, f.isSet(x_offset,y_offset)==false) // Flip the bit which corresponds to the diff+/-a radius distance
 		
 		//fmt.Printf("MutateMask flip bit (%2d,%2d)\n", x,y)
-		if rand.Intn(100)>another_mutation_pct {
+		if r.Intn(100)>another_mutation_pct {
 			break
 		}
 		//fmt.Printf("MutateMask round again\n")
@@ -255,10 +514,29 @@ func (f *Board_BoolPacked) MutateMask(mask *Board_BoolPacked, another_mutation_p
 
 
 
-func (offspring *Board_BoolPacked) CrossoverFrom_Horizontal(p1, p2 *Board_BoolPacked) { // OPTIMIZED FOR BoolPacked
-	offspring.s = make([]int32, board_height+2)
-	cross := rand.Intn(board_height+2)
-	for y := 0; y<board_height+2; y++ {
+// PerturbWithinMask returns a copy of f with each cell that is set in mask flipped with probability rate.
+// Cells outside the mask are never touched
+func (f *Board_BoolPacked) PerturbWithinMask(mask *Board_BoolPacked, rate float32, r *rand.Rand) *Board_BoolPacked {
+	perturbed := NewBoard_BoolPacked(f.w, f.h)
+	perturbed.CopyFrom(f)
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < f.w; x++ {
+			if mask.isSet(x, y) && r.Float32() < rate {
+				perturbed.Set(x, y, !perturbed.isSet(x, y))
+			}
+		}
+	}
+	return perturbed
+}
+
+func (offspring *Board_BoolPacked) CrossoverFrom_Horizontal(p1, p2 *Board_BoolPacked) {
+	offspring.CrossoverFrom_HorizontalR(p1, p2, rand_global)
+}
+
+func (offspring *Board_BoolPacked) CrossoverFrom_HorizontalR(p1, p2 *Board_BoolPacked, r *rand.Rand) { // OPTIMIZED FOR BoolPacked
+	offspring.must_match_dimensions(p2)
+	cross := r.Intn(p2.h+2)
+	for y := 0; y<p2.h+2; y++ {
 		if false && y<cross {
 			offspring.s[y] = p1.s[y]
 		} else {
@@ -267,7 +545,7 @@ func (offspring *Board_BoolPacked) CrossoverFrom_Horizontal(p1, p2 *Board_BoolPa
 	}
 /*
 	// offspring.CopyFrom(p1)
-	if(rand.Intn(100)>50) {
+	if(r.Intn(100)>50) {
 		// Horizontal dividing line
 		
 	} else {
@@ -277,15 +555,19 @@ func (offspring *Board_BoolPacked) CrossoverFrom_Horizontal(p1, p2 *Board_BoolPa
 }
 
 func (offspring *Board_BoolPacked) CrossoverFrom(p1, p2 *Board_BoolPacked) {
+	offspring.CrossoverFromR(p1, p2, rand_global)
+}
+
+func (offspring *Board_BoolPacked) CrossoverFromR(p1, p2 *Board_BoolPacked, r *rand.Rand) {
 	offspring.CopyFrom(p1) // Grab p1 ASAP
 	
 	// Pick a random location
-	src_x, src_y := rand.Intn(offspring.w), rand.Intn(offspring.h)
+	src_x, src_y := r.Intn(offspring.w), r.Intn(offspring.h)
 	
 	radius := 5
 	// Pick an L1 radius
-	r_up := rand.Intn(radius)
-	r_down := rand.Intn(radius)
+	r_up := r.Intn(radius)
+	r_down := r.Intn(radius)
 	
 	// Copy the rectangular blog from p2
 	for x:=src_x-r_down; x<=src_x+r_up; x++ {
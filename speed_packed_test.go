@@ -0,0 +1,598 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Each generator drives the same sequence of randomised operations from the same seed
+// in its own goroutine, and must end up with the same board
+func TestRandConcurrentSeeded(t *testing.T) {
+	cases := []struct {
+		name string
+		run  func(r *rand.Rand) *Board_BoolPacked
+	}{
+		{"UniformRandomR", func(r *rand.Rand) *Board_BoolPacked {
+			b := NewBoard_BoolPacked(board_width, board_height)
+			b.UniformRandomR(0.4, r)
+			return b
+		}},
+		{"MutateFlipBitsR", func(r *rand.Rand) *Board_BoolPacked {
+			b := NewBoard_BoolPacked(board_width, board_height)
+			b.UniformRandomR(0.5, r)
+			b.MutateFlipBitsR(50, r)
+			return b
+		}},
+		{"MutateMaskR", func(r *rand.Rand) *Board_BoolPacked {
+			b := NewBoard_BoolPacked(board_width, board_height)
+			b.UniformRandomR(0.4, r)
+			mask := NewBoard_BoolPacked(board_width, board_height)
+			mask.CopyFrom(b)
+			b.MutateMaskR(mask, 50, 2, r)
+			return b
+		}},
+		{"CrossoverFromR", func(r *rand.Rand) *Board_BoolPacked {
+			p1, p2 := NewBoard_BoolPacked(board_width, board_height), NewBoard_BoolPacked(board_width, board_height)
+			p1.UniformRandomR(0.3, r)
+			p2.UniformRandomR(0.6, r)
+			b := NewBoard_BoolPacked(board_width, board_height)
+			b.CrossoverFromR(p1, p2, r)
+			return b
+		}},
+	}
+	for _, c := range cases {
+		const runs = 4
+		out := make([]string, runs)
+		var wg sync.WaitGroup
+		for i := 0; i < runs; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				out[i] = c.run(rand.New(rand.NewSource(7))).toCompactString()
+			}(i)
+		}
+		wg.Wait()
+		for i := 1; i < runs; i++ {
+			if out[i] != out[0] {
+				t.Errorf("%s: run %d differs from run 0", c.name, i)
+			}
+		}
+		if other := c.run(rand.New(rand.NewSource(8))).toCompactString(); other == out[0] {
+			t.Errorf("%s: a different seed gave the same board", c.name)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	corner := func(w, h int) *Board_BoolPacked {
+		b := NewBoard_BoolPacked(w, h)
+		b.Set(0, 0, true)
+		b.Set(w-1, h-1, true)
+		return b
+	}
+	cases := []struct {
+		name    string
+		corrupt func(b *Board_BoolPacked)
+		w, h    int
+		valid   bool
+	}{
+		{"well-formed", func(b *Board_BoolPacked) {}, board_width, board_height, true},
+		{"widest board", func(b *Board_BoolPacked) {}, 30, 3, true},
+		{"stray bit right of the row", func(b *Board_BoolPacked) { b.s[3] |= 1 << uint(board_width+1) }, board_width, board_height, false},
+		{"stray bit left of the row", func(b *Board_BoolPacked) { b.s[5] |= 1 }, board_width, board_height, false},
+		{"top padding row", func(b *Board_BoolPacked) { b.s[0] = 4 }, board_width, board_height, false},
+		{"bottom padding row", func(b *Board_BoolPacked) { b.s[len(b.s)-1] = 4 }, board_width, board_height, false},
+		{"rows missing", func(b *Board_BoolPacked) { b.s = b.s[:len(b.s)-1] }, board_width, board_height, false},
+		{"non-positive height", func(b *Board_BoolPacked) { b.h = 0 }, board_width, board_height, false},
+		{"too wide", func(b *Board_BoolPacked) { b.w = 31 }, board_width, board_height, false},
+	}
+	for _, c := range cases {
+		b := corner(c.w, c.h)
+		c.corrupt(b)
+		if err := b.Validate(); (err == nil) != c.valid {
+			t.Errorf("%s: Validate() = %v", c.name, err)
+		}
+	}
+}
+
+// A glider moves one cell diagonally every 4 generations : On a torus it comes back to its start
+// once it has crossed the board both ways, and on the way it re-enters from the opposite edges
+func TestWrapGlider(t *testing.T) {
+	cases := []struct {
+		name   string
+		w, h   int
+		wrap   bool
+		period int // Generations for the glider to be back where it started, 0 for never
+	}{
+		{"torus 20x20", 20, 20, true, 80},
+		{"torus 10x15", 10, 15, true, 120},
+		{"torus 8x8", 8, 8, true, 32},
+		{"dead boundary 20x20", 20, 20, false, 0},
+	}
+	for _, c := range cases {
+		glider := board_from_rows(c.w, c.h, "-X-", "--X", "XXX")
+		glider.SetWrap(c.wrap)
+
+		generations := c.period
+		if generations == 0 {
+			generations = 80
+		}
+		cur, next := NewBoard_BoolPacked(c.w, c.h), NewBoard_BoolPacked(c.w, c.h)
+		cur.CopyFrom(glider)
+		reentered := false
+		for i := 1; i <= generations; i++ {
+			cur.Iterate(next)
+			cur, next = next, cur
+			if cur.wrap != c.wrap {
+				t.Fatalf("%s: wrap setting lost after %d generations", c.name, i)
+			}
+			if c.wrap && cur.Population() != 5 {
+				t.Fatalf("%s: population %d after %d generations, want the 5 glider cells", c.name, cur.Population(), i)
+			}
+			left, right, top, bottom := false, false, false, false
+			for y := 0; y < c.h; y++ {
+				left = left || cur.isSet(0, y)
+				right = right || cur.isSet(c.w-1, y)
+			}
+			for x := 0; x < c.w; x++ {
+				top = top || cur.isSet(x, 0)
+				bottom = bottom || cur.isSet(x, c.h-1)
+			}
+			reentered = reentered || (left && right) || (top && bottom)
+			if c.period != 0 && i < c.period && cur.CompareTo(glider, nil) == 0 {
+				t.Errorf("%s: back at the start after only %d generations", c.name, i)
+			}
+		}
+		if back := cur.CompareTo(glider, nil) == 0; back != (c.period != 0) {
+			t.Errorf("%s: back at the start after %d generations is %v", c.name, generations, back)
+		}
+		if reentered != c.wrap {
+			t.Errorf("%s: glider straddling opposite edges is %v, want %v", c.name, reentered, c.wrap)
+		}
+	}
+}
+
+// Every iteration path agrees on a wrapped board with live cells on the edges
+func TestWrapIterators(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		b := random_board(board_width, board_height, 0.4, seed)
+		b.SetWrap(true)
+		fast, generic := NewBoard_BoolPacked(b.w, b.h), NewBoard_BoolPacked(b.w, b.h)
+		b.Iterate(fast)
+		b.Iterate_Generic(generic)
+		if fast.CompareTo(generic, nil) != 0 {
+			t.Fatalf("seed %d: Iterate and Iterate_Generic disagree on a wrapped board", seed)
+		}
+		for y := 0; y < b.h; y++ {
+			for x := 0; x < b.w; x++ {
+				if b.IterateCell(x, y) != b.NextCellState(x, y, RuleConway, BoundaryWrap) {
+					t.Fatalf("seed %d: IterateCell(%d,%d) ignores the wrap setting", seed, x, y)
+				}
+			}
+		}
+	}
+
+	// Default stays the dead boundary : a blinker across the left/right edge dies out
+	b := board_from_rows(board_width, board_height, "", "", "X"+strings.Repeat("-", board_width-3)+"XX")
+	next := NewBoard_BoolPacked(b.w, b.h)
+	b.Iterate(next)
+	if next.Population() != 0 {
+		t.Errorf("unwrapped split blinker has %d live cells after a step, want 0", next.Population())
+	}
+	b.SetWrap(true)
+	b.Iterate(next)
+	if next.Population() != 3 || !next.isSet(board_width-1, 1) || !next.isSet(board_width-1, 3) {
+		t.Errorf("wrapped split blinker did not turn vertical on the last column")
+	}
+}
+
+// iterate_methods are the Iterate() implementations, all of which must agree with Iterate_Generic
+var iterate_methods = []struct {
+	name    string
+	iterate func(b, next *Board_BoolPacked)
+}{
+	{"WordParallel", (*Board_BoolPacked).Iterate},
+	{"ByColumn", (*Board_BoolPacked).Iterate_ByColumn},
+	{"Generic", (*Board_BoolPacked).Iterate_Generic},
+}
+
+// random_density_boards gives boards from sparse to dense, the same ones every time
+func random_density_boards(count int) []*Board_BoolPacked {
+	boards := make([]*Board_BoolPacked, count)
+	for i := range boards {
+		boards[i] = random_board(board_width, board_height, 0.05+0.9*float32(i)/float32(count), int64(i))
+	}
+	return boards
+}
+
+func TestIterateMatchesGeneric(t *testing.T) {
+	boards := random_density_boards(300)
+	next, check := NewBoard_BoolPacked(board_width, board_height), NewBoard_BoolPacked(board_width, board_height)
+	for _, method := range iterate_methods {
+		for i, b := range boards {
+			method.iterate(b, next)
+			b.Iterate_Generic(check)
+			if next.CompareTo(check, nil) != 0 || next.Validate() != nil {
+				t.Fatalf("%s: board %d differs from Iterate_Generic by %d cells", method.name, i, next.CompareTo(check, nil))
+			}
+		}
+	}
+
+	// BoardIterator.Iterate(n) goes through the fast path, and must land in the same place
+	for i, b := range boards[:50] {
+		l := NewBoardIterator(board_width, board_height)
+		l.current.CopyFrom(b)
+		l.Iterate(10)
+		check.CopyFrom(b)
+		for step := 0; step < 10; step++ {
+			check.Iterate_Generic(next)
+			check, next = next, check
+		}
+		if l.current.CompareTo(check, nil) != 0 {
+			t.Fatalf("BoardIterator: board %d differs from 10 Iterate_Generic steps", i)
+		}
+	}
+}
+
+func BenchmarkIterate(b *testing.B) {
+	boards := random_density_boards(300)
+	next := NewBoard_BoolPacked(board_width, board_height)
+	for _, method := range iterate_methods {
+		b.Run(method.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				method.iterate(boards[i%len(boards)], next)
+			}
+		})
+	}
+}
+
+func TestHammingDistanceAndDiff(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b *Board_BoolPacked
+	}{
+		{"identical", random_board(board_width, board_height, 0.4, 1), random_board(board_width, board_height, 0.4, 1)},
+		{"random pair", random_board(board_width, board_height, 0.5, 2), random_board(board_width, board_height, 0.5, 3)},
+		{"empty and full", NewBoard_BoolPacked(board_width, board_height), random_board(board_width, board_height, 1, 0)},
+		{"non-square", random_board(30, 25, 0.5, 4), random_board(30, 25, 0.5, 5)},
+		{"single row", board_from_rows(7, 1, "X-X-X"), board_from_rows(7, 1, "XX--X-X")},
+	}
+	for _, c := range cases {
+		diff := c.a.Diff(c.b)
+		differ := 0
+		for y := 0; y < c.a.h; y++ {
+			for x := 0; x < c.a.w; x++ {
+				mismatch := c.a.isSet(x, y) != c.b.isSet(x, y)
+				if mismatch {
+					differ++
+				}
+				if diff.isSet(x, y) != mismatch {
+					t.Errorf("%s: Diff at (%d,%d) is %v, want %v", c.name, x, y, diff.isSet(x, y), mismatch)
+				}
+			}
+		}
+		if d := c.a.HammingDistance(c.b); d != differ {
+			t.Errorf("%s: HammingDistance = %d, want %d", c.name, d, differ)
+		}
+		if d := c.b.HammingDistance(c.a); d != differ {
+			t.Errorf("%s: HammingDistance is not symmetric", c.name)
+		}
+		if err := diff.Validate(); err != nil {
+			t.Errorf("%s: Diff gave a malformed board : %v", c.name, err)
+		}
+	}
+
+	mismatched := []struct {
+		name string
+		f    func(a, b *Board_BoolPacked)
+	}{
+		{"HammingDistance", func(a, b *Board_BoolPacked) { a.HammingDistance(b) }},
+		{"Diff", func(a, b *Board_BoolPacked) { a.Diff(b) }},
+	}
+	for _, c := range mismatched {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic on mismatched dimensions", c.name)
+				}
+			}()
+			c.f(NewBoard_BoolPacked(20, 20), NewBoard_BoolPacked(20, 19))
+		}()
+	}
+}
+
+func TestPerturbWithinMask(t *testing.T) {
+	board := random_board(board_width, board_height, 0.5, 1)
+	full := random_board(board_width, board_height, 1, 0)
+	cases := []struct {
+		name        string
+		mask        *Board_BoolPacked
+		rate        float32
+		flips_every bool // rate 1 : every masked cell flips
+	}{
+		{"random mask", random_board(board_width, board_height, 0.3, 2), 0.5, false},
+		{"rectangle", board_from_rows(board_width, board_height, "", "", "--XXXXX", "--XXXXX", "--XXXXX"), 0.5, false},
+		{"empty mask", NewBoard_BoolPacked(board_width, board_height), 1, true},
+		{"rate 0", full, 0, false},
+		{"rate 1", random_board(board_width, board_height, 0.3, 3), 1, true},
+		{"full mask, rate 1", full, 1, true},
+	}
+	for _, c := range cases {
+		original := NewBoard_BoolPacked(board.w, board.h)
+		original.CopyFrom(board)
+		perturbed := board.PerturbWithinMask(c.mask, c.rate, rand.New(rand.NewSource(7)))
+		if board.CompareTo(original, nil) != 0 {
+			t.Fatalf("%s: the original board was modified", c.name)
+		}
+		flipped := board.Diff(perturbed)
+		outside := 0
+		for y := 0; y < board_height; y++ {
+			for x := 0; x < board_width; x++ {
+				if flipped.isSet(x, y) && !c.mask.isSet(x, y) {
+					outside++
+				}
+			}
+		}
+		if outside != 0 {
+			t.Errorf("%s: %d cells outside the mask changed", c.name, outside)
+		}
+		switch {
+		case c.rate == 0 && flipped.Population() != 0:
+			t.Errorf("%s: %d cells flipped at rate 0", c.name, flipped.Population())
+		case c.flips_every && flipped.CompareTo(c.mask, nil) != 0:
+			t.Errorf("%s: flips differ from the mask by %d cells at rate 1", c.name, flipped.CompareTo(c.mask, nil))
+		case c.rate == 0.5 && (flipped.Population() == 0 || flipped.Population() == c.mask.Population()):
+			t.Errorf("%s: %d of %d masked cells flipped at rate 0.5", c.name, flipped.Population(), c.mask.Population())
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	cases := []struct {
+		name string
+		w, h int
+		pct  float32
+	}{
+		{"empty", board_width, board_height, 0},
+		{"sparse", board_width, board_height, 0.1},
+		{"dense", board_width, board_height, 0.6},
+		{"non-square", 30, 25, 0.4},
+		{"tiny", 1, 1, 1},
+	}
+	for _, c := range cases {
+		b := random_board(c.w, c.h, c.pct, 1)
+		clone := NewBoard_BoolPacked(b.w, b.h)
+		clone.CopyFrom(b)
+		if b.Hash() != clone.Hash() {
+			t.Errorf("%s: clone hashes differently", c.name)
+		}
+		for _, cell := range [][2]int{{0, 0}, {c.w - 1, c.h - 1}, {c.w / 2, c.h / 2}} {
+			flipped := NewBoard_BoolPacked(b.w, b.h)
+			flipped.CopyFrom(b)
+			flipped.Set(cell[0], cell[1], !flipped.isSet(cell[0], cell[1]))
+			if flipped.Hash() == b.Hash() {
+				t.Errorf("%s: flipping (%d,%d) leaves the hash unchanged", c.name, cell[0], cell[1])
+			}
+		}
+	}
+
+	// Same (empty) contents, different shapes
+	if NewBoard_BoolPacked(20, 20).Hash() == NewBoard_BoolPacked(20, 21).Hash() {
+		t.Error("20x20 and 20x21 empty boards hash the same")
+	}
+
+	// Distinct boards almost never collide
+	seen := map[uint64]string{}
+	for seed := int64(0); seed < 2000; seed++ {
+		b := random_board(board_width, board_height, 0.4, seed)
+		if other, ok := seen[b.Hash()]; ok && other != b.toCompactString() {
+			t.Fatalf("seed %d: hash collision", seed)
+		}
+		seen[b.Hash()] = b.toCompactString()
+	}
+}
+
+func TestCloneAndCopyFrom(t *testing.T) {
+	cases := []struct {
+		name     string
+		original *Board_BoolPacked
+		wrap     bool
+	}{
+		{"random", random_board(board_width, board_height, 0.4, 1), false},
+		{"wrapped", random_board(board_width, board_height, 0.4, 2), true},
+		{"empty", NewBoard_BoolPacked(board_width, board_height), false},
+		{"non-square", random_board(25, 7, 0.5, 3), false},
+	}
+	for _, c := range cases {
+		c.original.SetWrap(c.wrap)
+		snapshot := c.original.toCompactString()
+
+		clone := c.original.Clone()
+		copied := NewBoard_BoolPacked(c.original.w, c.original.h)
+		rows := &copied.s[0]
+		copied.CopyFrom(c.original)
+		if &copied.s[0] != rows {
+			t.Errorf("%s: CopyFrom reallocated the rows rather than overwriting them", c.name)
+		}
+
+		for _, dup := range []struct {
+			name string
+			b    *Board_BoolPacked
+		}{{"Clone", clone}, {"CopyFrom", copied}} {
+			if dup.b.w != c.original.w || dup.b.h != c.original.h || dup.b.wrap != c.wrap || dup.b.CompareTo(c.original, nil) != 0 {
+				t.Errorf("%s: %s is not an exact copy", c.name, dup.name)
+			}
+
+			// Mutating the copy leaves the original alone...
+			dup.b.Set(0, 0, !dup.b.isSet(0, 0))
+			if c.original.toCompactString() != snapshot {
+				t.Errorf("%s: mutating the %s copy changed the original", c.name, dup.name)
+			}
+			// ... and vice versa
+			c.original.Set(1, 0, !c.original.isSet(1, 0))
+			if dup.b.isSet(1, 0) == c.original.isSet(1, 0) {
+				t.Errorf("%s: mutating the original changed the %s copy", c.name, dup.name)
+			}
+			c.original.Set(1, 0, !c.original.isSet(1, 0))
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("CopyFrom between different sizes did not panic")
+		}
+	}()
+	NewBoard_BoolPacked(5, 5).CopyFrom(NewBoard_BoolPacked(board_width, board_height))
+}
+
+func TestPopulationAndDensity(t *testing.T) {
+	cases := []struct {
+		name string
+		w, h int
+		pct  float32
+	}{
+		{"empty", board_width, board_height, 0},
+		{"full", board_width, board_height, 1},
+		{"sparse", board_width, board_height, 0.1},
+		{"dense", board_width, board_height, 0.7},
+		{"widest", 30, 4, 0.5},
+		{"single cell", 1, 1, 1},
+		{"column", 1, 25, 0.5},
+	}
+	for _, c := range cases {
+		for seed := int64(0); seed < 10; seed++ {
+			b := random_board(c.w, c.h, c.pct, seed)
+			count := 0
+			for y := 0; y < b.h; y++ {
+				for x := 0; x < b.w; x++ {
+					if b.isSet(x, y) {
+						count++
+					}
+				}
+			}
+			if b.Population() != count {
+				t.Errorf("%s, seed %d: Population() = %d, brute force %d", c.name, seed, b.Population(), count)
+			}
+			if want := float32(count) / float32(c.w*c.h); b.Density() != want {
+				t.Errorf("%s, seed %d: Density() = %v, want %v", c.name, seed, b.Density(), want)
+			}
+		}
+	}
+	if d := (&Board_BoolPacked{}).Density(); d != 0 {
+		t.Errorf("a board with no cells has density %v", d)
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	cases := []struct {
+		name                   string
+		board                  *Board_BoolPacked
+		minX, minY, maxX, maxY int
+		empty                  bool
+	}{
+		{"empty", NewBoard_BoolPacked(20, 15), 0, 0, 0, 0, true},
+		{"single cell", board_from_rows(20, 15, "", "", "", "", "", "", "", "----X"), 4, 7, 4, 7, false},
+		{"origin", board_from_rows(20, 15, "X"), 0, 0, 0, 0, false},
+		{"glider", board_from_rows(20, 15, "", "", "---X", "----X", "--XXX"), 2, 2, 4, 4, false},
+		{"touching all four edges", func() *Board_BoolPacked {
+			b := NewBoard_BoolPacked(20, 15)
+			b.SetCells([][2]int{{0, 5}, {19, 6}, {3, 0}, {8, 14}})
+			return b
+		}(), 0, 0, 19, 14, false},
+		{"widest board corner", func() *Board_BoolPacked {
+			b := NewBoard_BoolPacked(30, 2)
+			b.Set(29, 1, true)
+			return b
+		}(), 29, 1, 29, 1, false},
+		{"full", random_board(7, 9, 1, 0), 0, 0, 6, 8, false},
+	}
+	for _, c := range cases {
+		minX, minY, maxX, maxY, empty := c.board.BoundingBox()
+		if empty != c.empty || minX != c.minX || minY != c.minY || maxX != c.maxX || maxY != c.maxY {
+			t.Errorf("%s: got (%d,%d)-(%d,%d) empty=%v, want (%d,%d)-(%d,%d) empty=%v", c.name,
+				minX, minY, maxX, maxY, empty, c.minX, c.minY, c.maxX, c.maxY, c.empty)
+		}
+	}
+}
+
+func TestBoardPool(t *testing.T) {
+	pool := NewBoardPool(8, 6)
+	cases := []struct {
+		name string
+		put  *Board_BoolPacked
+	}{
+		{"dirty board comes back cleared", random_board(8, 6, 0.5, 1)},
+		{"wrap is reset", func() *Board_BoolPacked {
+			b := random_board(8, 6, 0.5, 2)
+			b.SetWrap(true)
+			return b
+		}()},
+		{"other sizes are not pooled", random_board(5, 5, 0.5, 3)},
+	}
+	for _, c := range cases {
+		pool.Put(c.put)
+		for i := 0; i < 3; i++ {
+			b := pool.Get()
+			if b.w != 8 || b.h != 6 || b.Population() != 0 || b.wrap {
+				t.Errorf("%s: Get returned a %dx%d board, %d live, wrap=%v", c.name, b.w, b.h, b.Population(), b.wrap)
+			}
+		}
+	}
+}
+
+func TestBooleanOps(t *testing.T) {
+	ops := []struct {
+		name string
+		op   func(a, b *Board_BoolPacked) *Board_BoolPacked
+		cell func(a, b bool) bool
+	}{
+		{"And", (*Board_BoolPacked).And, func(a, b bool) bool { return a && b }},
+		{"Or", (*Board_BoolPacked).Or, func(a, b bool) bool { return a || b }},
+		{"Xor", (*Board_BoolPacked).Xor, func(a, b bool) bool { return a != b }},
+		{"AndNot", (*Board_BoolPacked).AndNot, func(a, b bool) bool { return a && !b }},
+		{"Diff", (*Board_BoolPacked).Diff, func(a, b bool) bool { return a != b }},
+	}
+	sizes := []struct{ w, h int }{{board_width, board_height}, {30, 7}, {1, 1}, {9, 13}}
+	for _, op := range ops {
+		for _, size := range sizes {
+			for seed := int64(0); seed < 5; seed++ {
+				a, b := random_board(size.w, size.h, 0.5, seed), random_board(size.w, size.h, 0.5, seed+100)
+				a_before, b_before := a.Clone(), b.Clone()
+				got := op.op(a, b)
+				want := 0
+				for y := 0; y < size.h; y++ {
+					for x := 0; x < size.w; x++ {
+						cell := op.cell(a.isSet(x, y), b.isSet(x, y))
+						if got.isSet(x, y) != cell {
+							t.Fatalf("%s %dx%d seed %d: cell (%d,%d) = %v, want %v", op.name, size.w, size.h, seed, x, y, got.isSet(x, y), cell)
+						}
+						if cell {
+							want++
+						}
+					}
+				}
+				if got.Population() != want {
+					t.Errorf("%s %dx%d seed %d: population %d, want %d (live padding?)", op.name, size.w, size.h, seed, got.Population(), want)
+				}
+				if a.HammingDistance(a_before) != 0 || b.HammingDistance(b_before) != 0 {
+					t.Errorf("%s %dx%d seed %d: changed its inputs", op.name, size.w, size.h, seed)
+				}
+			}
+		}
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic for boards of different sizes", op.name)
+				}
+			}()
+			op.op(NewBoard_BoolPacked(5, 5), NewBoard_BoolPacked(5, 6))
+		}()
+	}
+}
@@ -0,0 +1,137 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+// Board is what the different board representations have in common
+type Board interface {
+	Set(x, y int, b bool)
+	isSet(x, y int) bool
+	get_width() int
+	get_height() int
+
+	Population() int
+	NextGeneration() Board // A new board (of the same representation) one step on
+	String() string
+}
+
+func (f *Board_BoolPacked) get_width() int  { return f.w }
+func (f *Board_BoolPacked) get_height() int { return f.h }
+
+func (f *Board_BoolPacked) NextGeneration() Board {
+	next := NewBoard_BoolPacked(f.w, f.h)
+	f.Iterate(next)
+	return next
+}
+
+// Board_Sparse just keeps the set of live cells : Much smaller than packed rows for nearly-empty boards
+// (the dead boundary still applies : nothing lives outside w x h)
+type Board_Sparse struct {
+	live map[[2]int]bool
+	w, h int
+}
+
+func NewBoard_Sparse(w, h int) *Board_Sparse {
+	return &Board_Sparse{live: make(map[[2]int]bool), w: w, h: h}
+}
+
+func (f *Board_Sparse) Set(x, y int, b bool) {
+	if b {
+		f.live[[2]int{x, y}] = true
+	} else {
+		delete(f.live, [2]int{x, y})
+	}
+}
+
+func (f *Board_Sparse) isSet(x, y int) bool {
+	return f.live[[2]int{x, y}]
+}
+
+func (f *Board_Sparse) get_width() int  { return f.w }
+func (f *Board_Sparse) get_height() int { return f.h }
+
+func (f *Board_Sparse) Population() int {
+	return len(f.live)
+}
+
+func (f *Board_Sparse) NextGeneration() Board {
+	// Only cells next to a live cell can be alive next time
+	neighbours := make(map[[2]int]int)
+	for c := range f.live {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if dx != 0 || dy != 0 {
+					neighbours[[2]int{c[0]+dx, c[1]+dy}]++
+				}
+			}
+		}
+	}
+
+	next := NewBoard_Sparse(f.w, f.h)
+	for c, alive := range neighbours {
+		if c[0]<0 || c[0]>=f.w || c[1]<0 || c[1]>=f.h {
+			continue
+		}
+		if alive == 3 || alive == 2 && f.live[c] {
+			next.live[c] = true
+		}
+	}
+	return next
+}
+
+func (f *Board_Sparse) to_packed() *Board_BoolPacked {
+	packed := NewBoard_BoolPacked(f.w, f.h)
+	for c := range f.live {
+		packed.Set(c[0], c[1], true)
+	}
+	return packed
+}
+
+// String uses the same layout as Board_BoolPacked.String()
+func (f *Board_Sparse) String() string {
+	return f.to_packed().String()
+}
+
+// Below this fraction of live cells, the sparse representation wins
+const sparse_density_threshold float32 = 0.05
+
+// ChooseBoardForDensity returns an empty board in whichever representation suits the expected density
+func ChooseBoardForDensity(w, h int, density float32) Board {
+	if density < sparse_density_threshold {
+		return NewBoard_Sparse(w, h)
+	}
+	return NewBoard_BoolPacked(w, h)
+}
+
+// BoundaryEffect counts the cells of b that come out differently after steps generations with the dead boundary,
+// compared with b on a board padded by steps cells all round (where the boundary can't reach back in time).
+// 0 means the pattern evolves as it would on an infinite plane
+func BoundaryEffect(b *Board_BoolPacked, steps int) int {
+	l := NewBoardIterator(b.w, b.h)
+	l.current.CopyFrom(b)
+	l.current.SetWrap(false)
+	l.Iterate(steps)
+
+	// The padded board could be too wide to pack, so it is sparse
+	var padded Board = NewBoard_Sparse(b.w+2*steps, b.h+2*steps)
+	for y := 0; y < b.h; y++ {
+		for x := 0; x < b.w; x++ {
+			if b.isSet(x, y) {
+				padded.Set(x+steps, y+steps, true)
+			}
+		}
+	}
+	for i := 0; i < steps; i++ {
+		padded = padded.NextGeneration()
+	}
+
+	differ := 0
+	for y := 0; y < b.h; y++ {
+		for x := 0; x < b.w; x++ {
+			if l.current.isSet(x, y) != padded.isSet(x+steps, y+steps) {
+				differ++
+			}
+		}
+	}
+	return differ
+}
@@ -0,0 +1,78 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"testing"
+)
+
+func TestChooseBoardForDensity(t *testing.T) {
+	cases := []struct {
+		name    string
+		density float32
+		sparse  bool
+	}{
+		{"1% dense", 0.01, true},
+		{"just under the threshold", sparse_density_threshold - 0.001, true},
+		{"at the threshold", sparse_density_threshold, false},
+		{"40% dense", 0.4, false},
+	}
+	for _, c := range cases {
+		b := ChooseBoardForDensity(board_width, board_height, c.density)
+		if _, sparse := b.(*Board_Sparse); sparse != c.sparse {
+			t.Errorf("%s: got %T", c.name, b)
+		}
+
+		// Whatever the representation, iterating must agree with the packed board
+		want := random_board(board_width, board_height, c.density*4, 7)
+		for y := 0; y < board_height; y++ {
+			for x := 0; x < board_width; x++ {
+				b.Set(x, y, want.isSet(x, y))
+			}
+		}
+		for step := 1; step <= 8; step++ {
+			b = b.NextGeneration()
+			want = forward(want, 1)
+			if b.Population() != want.Population() {
+				t.Fatalf("%s: step %d population %d, packed %d", c.name, step, b.Population(), want.Population())
+			}
+			for y := 0; y < board_height; y++ {
+				for x := 0; x < board_width; x++ {
+					if b.isSet(x, y) != want.isSet(x, y) {
+						t.Fatalf("%s: step %d differs from packed at (%d,%d)", c.name, step, x, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBoundaryEffect(t *testing.T) {
+	cells := func(cells ...[2]int) *Board_BoolPacked {
+		b := NewBoard_BoolPacked(board_width, board_height)
+		b.SetCells(cells)
+		return b
+	}
+	cases := []struct {
+		name      string
+		board     *Board_BoolPacked
+		steps     int
+		truncated bool // Whether the dead boundary changes the outcome
+	}{
+		{"empty", NewBoard_BoolPacked(board_width, board_height), 10, false},
+		{"centred pattern", cells([2]int{9, 9}, [2]int{10, 9}, [2]int{11, 9}, [2]int{9, 10}), 5, false},
+		{"block in the corner", cells([2]int{0, 0}, [2]int{1, 0}, [2]int{0, 1}, [2]int{1, 1}), 10, false},
+		// Half of the horizontal phase of an edge blinker falls off the board, which only shows a step later
+		{"edge blinker, 1 step", cells([2]int{0, 5}, [2]int{0, 6}, [2]int{0, 7}), 1, false},
+		{"edge blinker, 2 steps", cells([2]int{0, 5}, [2]int{0, 6}, [2]int{0, 7}), 2, true},
+		{"glider into the corner", cells([2]int{17, 16}, [2]int{18, 17}, [2]int{16, 18}, [2]int{17, 18}, [2]int{18, 18}), 8, true},
+		{"glider away from the corner", cells([2]int{1, 0}, [2]int{2, 1}, [2]int{0, 2}, [2]int{1, 2}, [2]int{2, 2}), 8, false},
+	}
+	for _, c := range cases {
+		effect := BoundaryEffect(c.board, c.steps)
+		if (effect != 0) != c.truncated {
+			t.Errorf("%s: BoundaryEffect = %d, want truncated=%v", c.name, effect, c.truncated)
+		}
+	}
+}
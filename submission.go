@@ -0,0 +1,238 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Same layout as create_submission() : "id,start.1,...,start.400", then one row per id
+func write_submission_header(w io.Writer, cells int) error {
+	header := "id"
+	for i := 1; i <= cells; i++ {
+		header += fmt.Sprintf(",start.%d", i)
+	}
+	_, err := io.WriteString(w, header+"\n")
+	return err
+}
+
+func write_submission_row(w io.Writer, id int, start *Board_BoolPacked) error {
+	_, err := io.WriteString(w, fmt.Sprintf("%d%s\n", id, start.toCSV()))
+	return err
+}
+
+// Reads the id column of a CSV file with an 'id' header (e.g. Kaggle's sampleSubmission.csv), in file order
+func read_submission_ids(path string) ([]int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s : reading header : %v", path, err)
+	}
+	if header[0] != "id" {
+		return nil, fmt.Errorf("%s : bad header, expected 'id' first", path)
+	}
+
+	ids := []int{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%s : %v", path, err)
+		}
+		id, err := strconv.Atoi(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s : bad id '%s'", path, record[0])
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// WriteSubmission writes a prediction for every problem in the set, in ascending id order (as in the input files).
+// Every problem must have a prediction (nothing is written otherwise)
+func (s *LifeProblemSet) WriteSubmission(path string, predictions map[int]*Board_BoolPacked) error {
+	ids := []int{}
+	for id := range s.problem {
+		if predictions[id] == nil {
+			return fmt.Errorf("no prediction for id %d", id)
+		}
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+
+	cells := board_width*board_height
+	if len(ids) > 0 {
+		cells = predictions[ids[0]].w * predictions[ids[0]].h
+	}
+	if err := write_submission_header(w, cells); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := write_submission_row(w, id, predictions[id]); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// WriteSubmissionOrdered writes the predictions with rows in exactly the order of the sample submission file.
+// Every id in the sample must have a prediction (nothing is written otherwise)
+func WriteSubmissionOrdered(path string, predictions map[int]*Board_BoolPacked, sampleSubmissionPath string) error {
+	ids, err := read_submission_ids(sampleSubmissionPath)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if predictions[id] == nil {
+			return fmt.Errorf("no prediction for id %d", id)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+
+	cells := board_width*board_height
+	if len(ids) > 0 {
+		cells = predictions[ids[0]].w * predictions[ids[0]].h
+	}
+	if err := write_submission_header(w, cells); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := write_submission_row(w, id, predictions[id]); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// WriteConfidenceCSV writes one problem's per-cell probabilities of being alive (indexed [y][x]), 
+// in the submission layout (header, then the id and the cells in row-major order), for blending elsewhere
+func WriteConfidenceCSV(path string, id int, prob [][]float64) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+
+	cells := 0
+	for _, row := range prob {
+		cells += len(row)
+	}
+	if err := write_submission_header(w, cells); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, strconv.Itoa(id)); err != nil {
+		return err
+	}
+	for _, row := range prob {
+		for _, p := range row {
+			if _, err := io.WriteString(w, ","+strconv.FormatFloat(p, 'f', 6, 64)); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// SubmissionWriter streams a submission file out as problems finish, rather than holding every prediction.
+// Rows appear in the order they are added : add in id order if the file needs to be sorted.
+// Add is safe to call from several workers
+type SubmissionWriter struct {
+	mutex sync.Mutex
+	file  *os.File
+	w     *bufio.Writer
+}
+
+// NewSubmissionWriter creates the file and writes the header (for board_width x board_height boards)
+func NewSubmissionWriter(path string) (*SubmissionWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(file)
+	if err := write_submission_header(w, board_width*board_height); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &SubmissionWriter{file: file, w: w}, nil
+}
+
+func (sw *SubmissionWriter) Add(id int, start *Board_BoolPacked) error {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+	return write_submission_row(sw.w, id, start)
+}
+
+// Close flushes the remaining rows and closes the file
+func (sw *SubmissionWriter) Close() error {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+	if err := sw.w.Flush(); err != nil {
+		sw.file.Close()
+		return err
+	}
+	return sw.file.Close()
+}
+
+// ValidateSubmission compares the ids in a submission file with a test set :
+// missing are test ids with no row, extra are rows for ids not in the test set (both sorted)
+func ValidateSubmission(submissionPath string, testSet *LifeProblemSet) (missing []int, extra []int, err error) {
+	ids, err := read_submission_ids(submissionPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	submitted := make(map[int]bool)
+	for _, id := range ids {
+		submitted[id] = true
+		if _, ok := testSet.problem[id]; !ok {
+			extra = append(extra, id)
+		}
+	}
+	for id := range testSet.problem {
+		if !submitted[id] {
+			missing = append(missing, id)
+		}
+	}
+	sort.Ints(missing)
+	sort.Ints(extra)
+	return missing, extra, nil
+}
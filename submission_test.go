@@ -0,0 +1,312 @@
+// An implementation of Conway's Game of Life.
+// See reverse-gol.go for build/run
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Writes a sample submission file listing ids in the given order
+func write_sample_submission(t *testing.T, path string, ids []int) {
+	sample := "id,start.1\n"
+	for _, id := range ids {
+		sample += fmt.Sprintf("%d,0\n", id)
+	}
+	if err := os.WriteFile(path, []byte(sample), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteSubmissionOrdered(t *testing.T) {
+	predictions := map[int]*Board_BoolPacked{}
+	for id := 1; id <= 5; id++ {
+		predictions[id] = random_board(board_width, board_height, 0.3, int64(id))
+	}
+	dir := t.TempDir()
+	cases := []struct {
+		name    string
+		order   []int
+		out     string // "" for a file in dir
+		wantErr bool
+	}{
+		{"unsorted", []int{3, 1, 2}, "", false},
+		{"descending", []int{5, 4, 3, 2, 1}, "", false},
+		{"subset", []int{4}, "", false},
+		{"missing prediction", []int{2, 6, 1}, "", true},
+		{"unwritable path", []int{1, 2}, filepath.Join(dir, "absent", "out.csv"), true},
+		{"write fails", []int{1, 2, 3, 4, 5}, "/dev/full", true},
+	}
+	for _, c := range cases {
+		sample, out := filepath.Join(dir, "sample.csv"), filepath.Join(dir, "out.csv")
+		if c.out != "" {
+			out = c.out
+		}
+		if _, err := os.Stat(out); out == "/dev/full" && err != nil {
+			continue // Not on this platform
+		}
+		write_sample_submission(t, sample, c.order)
+		err := WriteSubmissionOrdered(out, predictions, sample)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(lines) != len(c.order)+1 {
+			t.Fatalf("%s: %d lines, want a header and %d rows", c.name, len(lines), len(c.order))
+		}
+		for i, id := range c.order {
+			if want := fmt.Sprintf("%d%s", id, predictions[id].toCSV()); lines[i+1] != want {
+				t.Errorf("%s: row %d is not id %d's prediction", c.name, i+1, id)
+			}
+		}
+	}
+}
+
+func TestWriteConfidenceCSV(t *testing.T) {
+	grid := func(w, h int, value func(x, y int) float64) [][]float64 {
+		prob := make([][]float64, h)
+		for y := range prob {
+			prob[y] = make([]float64, w)
+			for x := range prob[y] {
+				prob[y][x] = value(x, y)
+			}
+		}
+		return prob
+	}
+	r := rand.New(rand.NewSource(1))
+	cases := []struct {
+		name string
+		id   int
+		prob [][]float64
+	}{
+		{"random 20x20", 17, grid(board_width, board_height, func(x, y int) float64 { return r.Float64() })},
+		{"certain", 2, grid(board_width, board_height, func(x, y int) float64 { return float64((x + y) % 2) })},
+		{"tiny non-square", 3, grid(3, 2, func(x, y int) float64 { return float64(x) / 7 })},
+	}
+	dir := t.TempDir()
+	for _, c := range cases {
+		path := filepath.Join(dir, "confidence.csv")
+		if err := WriteConfidenceCSV(path, c.id, c.prob); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records, err := csv.NewReader(file).ReadAll()
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w, h := len(c.prob[0]), len(c.prob)
+		if len(records) != 2 || len(records[0]) != 1+w*h || records[0][0] != "id" || records[0][w*h] != fmt.Sprintf("start.%d", w*h) {
+			t.Fatalf("%s: unexpected layout %d records, header %v...", c.name, len(records), records[0][:2])
+		}
+		if records[1][0] != strconv.Itoa(c.id) {
+			t.Errorf("%s: id column is %s, want %d", c.name, records[1][0], c.id)
+		}
+		for i, field := range records[1][1:] {
+			p, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			}
+			if want := c.prob[i/w][i%w]; math.Abs(p-want) > 1e-6 {
+				t.Errorf("%s: cell %d read back as %v, want %v", c.name, i, p, want)
+			}
+		}
+	}
+}
+
+func TestSubmissionWriter(t *testing.T) {
+	cases := []struct {
+		name string
+		ids  []int
+	}{
+		{"in id order", []int{1, 2, 3, 10}},
+		{"in finishing order", []int{7, 3, 5}},
+		{"no rows", nil},
+	}
+	dir := t.TempDir()
+	for _, c := range cases {
+		path := filepath.Join(dir, "submission.csv")
+		sw, err := NewSubmissionWriter(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		predictions := map[int]*Board_BoolPacked{}
+		for _, id := range c.ids {
+			predictions[id] = random_board(board_width, board_height, 0.3, int64(id))
+			if err := sw.Add(id, predictions[id]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := sw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		ids, err := read_submission_ids(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(c.ids) && len(c.ids) > 0 {
+			t.Errorf("%s: rows are in order %v, want %v", c.name, ids, c.ids)
+		}
+
+		// A submission reads back as a test set (no steps column) whose ends are the predictions
+		var reloaded LifeProblemSet
+		reloaded.load_csv_from_file(path, false, false, c.ids)
+		if len(reloaded.problem) != len(c.ids) {
+			t.Errorf("%s: reloaded %d problems, want %d", c.name, len(reloaded.problem), len(c.ids))
+		}
+		for _, id := range c.ids {
+			if reloaded.problem[id].end.CompareTo(predictions[id], nil) != 0 {
+				t.Errorf("%s: id %d doesn't reload as written", c.name, id)
+			}
+		}
+	}
+}
+
+func TestValidateSubmission(t *testing.T) {
+	testSet := &LifeProblemSet{problem: map[int]LifeProblem{1: {}, 2: {}, 3: {}, 4: {}}}
+	cases := []struct {
+		name           string
+		ids            []int
+		missing, extra []int
+	}{
+		{"complete", []int{1, 2, 3, 4}, nil, nil},
+		{"complete, out of order", []int{4, 2, 1, 3}, nil, nil},
+		{"one missing, one spurious", []int{1, 2, 4, 99}, []int{3}, []int{99}},
+		{"several of each", []int{7, 4, 5}, []int{1, 2, 3}, []int{5, 7}},
+		{"empty", []int{}, []int{1, 2, 3, 4}, nil},
+	}
+	path := filepath.Join(t.TempDir(), "submission.csv")
+	for _, c := range cases {
+		write_sample_submission(t, path, c.ids)
+		missing, extra, err := ValidateSubmission(path, testSet)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if fmt.Sprint(missing) != fmt.Sprint(c.missing) || fmt.Sprint(extra) != fmt.Sprint(c.extra) {
+			t.Errorf("%s: got missing=%v extra=%v, want missing=%v extra=%v", c.name, missing, extra, c.missing, c.extra)
+		}
+	}
+
+	bad := []struct {
+		name, contents string
+	}{
+		{"bad header", "start.1,id\n0,1\n"},
+		{"bad id", "id,start.1\nfoo,0\n"},
+		{"empty file", ""},
+	}
+	for _, c := range bad {
+		if err := os.WriteFile(path, []byte(c.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := ValidateSubmission(path, testSet); err == nil {
+			t.Errorf("%s: no error", c.name)
+		}
+	}
+	if _, _, err := ValidateSubmission(filepath.Join(t.TempDir(), "absent.csv"), testSet); err == nil {
+		t.Errorf("missing file : no error")
+	}
+}
+
+// Loading a test file and writing its end boards back out as a submission gives the same rows,
+// sorted by id, with the steps column dropped and the columns renamed
+func TestWriteSubmissionRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		ids  []int
+	}{
+		{"sorted", []int{1, 2, 3}},
+		{"unsorted", []int{50, 7, 12, 8}},
+		{"single", []int{42}},
+	}
+	dir := t.TempDir()
+	for _, c := range cases {
+		header := []string{"id", "delta"}
+		for i := 1; i <= board_width*board_height; i++ {
+			header = append(header, fmt.Sprintf("stop.%d", i))
+		}
+		input := strings.Join(header, ",") + "\n"
+		rows := map[int]string{}
+		for _, id := range c.ids {
+			b, cells := random_board(board_width, board_height, 0.3, int64(id)), []string{}
+			for y := 0; y < b.h; y++ {
+				for x := 0; x < b.w; x++ {
+					cells = append(cells, map[bool]string{false: "0", true: "1"}[b.isSet(x, y)])
+				}
+			}
+			rows[id] = strings.Join(cells, ",")
+			input += fmt.Sprintf("%d,%d,%s\n", id, 1+id%5, rows[id])
+		}
+		in, out := filepath.Join(dir, "test.csv"), filepath.Join(dir, "submission.csv")
+		if err := os.WriteFile(in, []byte(input), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var s LifeProblemSet
+		s.load_csv_from_file(in, false, true, c.ids)
+		predictions := map[int]*Board_BoolPacked{}
+		for id, problem := range s.problem {
+			predictions[id] = problem.end
+		}
+		if err := s.WriteSubmission(out, predictions); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		header = []string{"id"}
+		for i := 1; i <= board_width*board_height; i++ {
+			header = append(header, fmt.Sprintf("start.%d", i))
+		}
+		want := strings.Join(header, ",") + "\n"
+		sorted := append([]int{}, c.ids...)
+		sort.Ints(sorted)
+		for _, id := range sorted {
+			want += fmt.Sprintf("%d,%s\n", id, rows[id])
+		}
+		got, _ := os.ReadFile(out)
+		if string(got) != want {
+			t.Errorf("%s: submission differs from the loaded file", c.name)
+		}
+	}
+}
+
+func TestWriteSubmissionErrors(t *testing.T) {
+	s := &LifeProblemSet{problem: map[int]LifeProblem{1: {id: 1}, 2: {id: 2}}}
+	board := NewBoard_BoolPacked(board_width, board_height)
+	cases := []struct {
+		name        string
+		path        string
+		predictions map[int]*Board_BoolPacked
+	}{
+		{"missing prediction", filepath.Join(t.TempDir(), "a.csv"), map[int]*Board_BoolPacked{1: board}},
+		{"unwritable path", filepath.Join(t.TempDir(), "absent", "a.csv"), map[int]*Board_BoolPacked{1: board, 2: board}},
+	}
+	for _, c := range cases {
+		if err := s.WriteSubmission(c.path, c.predictions); err == nil {
+			t.Errorf("%s: no error", c.name)
+		}
+	}
+}
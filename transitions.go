@@ -123,12 +123,16 @@ func (p Patch) BestOrientation() PatchOrientation {
 }
 
 func (tc *TransitionCollectionList) GetRandomEntry_OrientationCompensated(q Patch) Patch {
+	return tc.GetRandomEntry_OrientationCompensatedR(q, rand_global)
+}
+
+func (tc *TransitionCollectionList) GetRandomEntry_OrientationCompensatedR(q Patch, r *rand.Rand) Patch {
 	oriented := q.BestOrientation()
 	
 	if pl, ok :=tc.pre[oriented.patch]; ok {
 		// if found, then copy a random one of its starters into the new individual
 		//fmt.Printf("Found known end!\n")
-		p := pl.GetRandomEntry()
+		p := pl.GetRandomEntryR(r)
 		
 		// Do the same (best) orientation maneuver on p
 		if oriented.flip_ud {
@@ -196,17 +200,17 @@ func (starts PatchMap) GetRandomEntryUNUSED() Patch {  // Not in use because of
 }
 */
 
-func (pl PatchList) GetRandomEntry_v1002() Patch {
+func (pl PatchList) GetRandomEntry_v1002(r *rand.Rand) Patch {
 	n_starts := len(pl.starts)
-	start_random_index := rand.Intn(n_starts)
+	start_random_index := r.Intn(n_starts)
 	return pl.starts[start_random_index].patch
 }
 // v1016 :: This makes it more likely to pick something near the beginning of the list
-func (pl PatchList) GetRandomEntry_v1016() Patch {
+func (pl PatchList) GetRandomEntry_v1016(r *rand.Rand) Patch {
 	n_starts := len(pl.starts)
-	start_random_index1 := rand.Intn(n_starts)
-	start_random_index2 := rand.Intn(n_starts)
-	if rand.Intn(100)<90 {
+	start_random_index1 := r.Intn(n_starts)
+	start_random_index2 := r.Intn(n_starts)
+	if r.Intn(100)<90 {
 		if start_random_index2<start_random_index1 {
 			start_random_index1=start_random_index2
 		}
@@ -215,8 +219,8 @@ func (pl PatchList) GetRandomEntry_v1016() Patch {
 }
 
 // v1018 :: This picks according to frequency distribution
-func (pl PatchList) GetRandomEntry_v1018() Patch {
-	random_index := rand.Intn(pl.freq_total)
+func (pl PatchList) GetRandomEntry_v1018(r *rand.Rand) Patch {
+	random_index := r.Intn(pl.freq_total)
 
 	patch := Patch(-1)
 	acc:=0
@@ -231,7 +235,11 @@ func (pl PatchList) GetRandomEntry_v1018() Patch {
 }
 
 func (pl PatchList) GetRandomEntry() Patch {
-	return pl.GetRandomEntry_v1016()
+	return pl.GetRandomEntryR(rand_global)
+}
+
+func (pl PatchList) GetRandomEntryR(r *rand.Rand) Patch {
+	return pl.GetRandomEntry_v1016(r)
 }
 
 type TransitionCollectionMap struct {